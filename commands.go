@@ -0,0 +1,470 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/gustapinto/pg_gen/pggen"
+)
+
+func newFlagSet(command string) (*flag.FlagSet, *string) {
+	flags := flag.NewFlagSet(command, flag.ExitOnError)
+	configPath := flags.String("config", "", "The config JSON or YAML file path")
+
+	return flags, configPath
+}
+
+func loadGenerator(configPath string) (*pggen.PgCodeGenerator, error) {
+	config, err := pggen.LoadConfigFromFile(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	return pggen.NewPgCodeGenerator(config)
+}
+
+func runGenerate(ctx context.Context, args []string) error {
+	flags, configPath := newFlagSet("generate")
+	dryRun := flags.Bool("dry-run", false, "List the files that would be changed, without writing them")
+	stdout := flags.Bool("stdout", false, "Print the generated code to stdout, without writing it")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *dryRun && *stdout {
+		return errors.New("-dry-run and -stdout must not be used together")
+	}
+
+	pgGen, err := loadGenerator(*configPath)
+	if err != nil {
+		return err
+	}
+	defer pgGen.Close()
+
+	if !*dryRun && !*stdout {
+		return pgGen.Generate(ctx)
+	}
+
+	files, err := pgGen.IntrospectAndRender(ctx)
+	if err != nil {
+		return err
+	}
+
+	if *stdout {
+		pggen.PrintFiles(os.Stdout, files)
+		return nil
+	}
+
+	changes, err := pgGen.PlanChanges(files)
+	if err != nil {
+		return err
+	}
+
+	pggen.PrintChanges(os.Stdout, changes)
+
+	return nil
+}
+
+func runCheck(ctx context.Context, args []string) error {
+	flags, configPath := newFlagSet("check")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	pgGen, err := loadGenerator(*configPath)
+	if err != nil {
+		return err
+	}
+	defer pgGen.Close()
+
+	files, err := pgGen.IntrospectAndRender(ctx)
+	if err != nil {
+		return err
+	}
+
+	changes, err := pgGen.PlanChanges(files)
+	if err != nil {
+		return err
+	}
+
+	staleFiles := 0
+	for _, change := range changes {
+		if change.IsStale() {
+			log.Printf("- Stale [%s], %s\n", change.Path, change.Reason)
+			staleFiles++
+		}
+	}
+
+	if staleFiles > 0 {
+		return fmt.Errorf("%d generated files are stale, run pg_gen generate to update them", staleFiles)
+	}
+
+	log.Printf("Generated files are up to date")
+
+	return nil
+}
+
+func runDiff(ctx context.Context, args []string) error {
+	flags, configPath := newFlagSet("diff")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	pgGen, err := loadGenerator(*configPath)
+	if err != nil {
+		return err
+	}
+	defer pgGen.Close()
+
+	files, err := pgGen.IntrospectAndRender(ctx)
+	if err != nil {
+		return err
+	}
+
+	changes, err := pgGen.PlanChanges(files)
+	if err != nil {
+		return err
+	}
+
+	for _, change := range changes {
+		fromName := "a/" + filepath.ToSlash(change.Path)
+		if change.Action == pggen.CreateFile {
+			fromName = "/dev/null"
+		}
+
+		toName := "b/" + filepath.ToSlash(change.Path)
+		if change.Action == pggen.DeleteFile {
+			toName = "/dev/null"
+		}
+
+		fmt.Print(pggen.UnifiedDiff(fromName, toName, change.Previous, change.File.Content))
+	}
+
+	return nil
+}
+
+func runInspect(ctx context.Context, args []string) error {
+	flags, configPath := newFlagSet("inspect")
+	out := flags.String("out", "", "The schema file path the model will be written to, instead of stdout")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	pgGen, err := loadGenerator(*configPath)
+	if err != nil {
+		return err
+	}
+	defer pgGen.Close()
+
+	schemas, err := pgGen.Introspect(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !strIsEmpty(*out) {
+		return pggen.WriteSchemaSnapshot(*out, schemas)
+	}
+
+	model, err := json.MarshalIndent(schemas, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(string(model))
+
+	return nil
+}
+
+func runDrift(ctx context.Context, args []string) error {
+	flags, configPath := newFlagSet("drift")
+	schemaFile := flags.String("schema-file", "", "The schema file the database is compared to, defaults to $.schema_file")
+	format := flags.String("format", "text", "The output format, text or json")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *format != "text" && *format != "json" {
+		return fmt.Errorf("invalid format [%s], it must be text or json", *format)
+	}
+
+	config, err := pggen.LoadConfigFromFile(*configPath)
+	if err != nil {
+		return err
+	}
+
+	if err := config.Validate(); err != nil {
+		return err
+	}
+
+	snapshotPath := *schemaFile
+	if strIsEmpty(snapshotPath) {
+		snapshotPath = config.SchemaFile
+	}
+
+	if strIsEmpty(snapshotPath) {
+		return errors.New("a schema file must be set by $.schema_file or the -schema-file flag")
+	}
+
+	snapshot, err := pggen.LoadModel(ctx, config, snapshotPath)
+	if err != nil {
+		return err
+	}
+
+	schemas, err := pggen.LoadModel(ctx, config, pggen.LiveDatabaseSource)
+	if err != nil {
+		return err
+	}
+
+	changes := pggen.DiffSchemas(snapshot, schemas)
+	if *format == "json" {
+		if changes == nil {
+			changes = []pggen.SchemaChange{}
+		}
+
+		content, err := json.MarshalIndent(changes, "", "  ")
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(string(content))
+	} else {
+		pggen.PrintSchemaChanges(os.Stdout, changes)
+	}
+
+	if len(changes) > 0 {
+		return fmt.Errorf("database schema drifted from schema file [%s], %d changes found", snapshotPath, len(changes))
+	}
+
+	log.Printf("Database schema matches schema file [%s]", snapshotPath)
+
+	return nil
+}
+
+func runMigrateDiff(ctx context.Context, args []string) error {
+	flags, configPath := newFlagSet("migrate-diff")
+	from := flags.String("from", "", "The model the migration starts from, a schema file, a migrations path or db for the database")
+	to := flags.String("to", pggen.LiveDatabaseSource, "The model the migration leads to, a schema file, a migrations path or db for the database")
+	out := flags.String("out", "", "The directory the up and down migration files are written to, instead of stdout")
+	name := flags.String("name", "schema_changes", "The name of the migration files")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if strIsEmpty(*from) {
+		return errors.New("the -from flag must be present and not be blank")
+	}
+
+	config, err := pggen.LoadConfigFromFile(*configPath)
+	if err != nil {
+		return err
+	}
+
+	if err := config.Validate(); err != nil {
+		return err
+	}
+
+	fromModel, err := pggen.LoadModel(ctx, config, *from)
+	if err != nil {
+		return err
+	}
+
+	toModel, err := pggen.LoadModel(ctx, config, *to)
+	if err != nil {
+		return err
+	}
+
+	files := []pggen.GeneratedFile{
+		pggen.MigrationFile(
+			filepath.Join(*out, *name+".up.sql"),
+			"Up migration generated by pg_gen migrate-diff, from ["+*from+"] to ["+*to+"]",
+			pggen.DiffSchemas(fromModel, toModel)),
+		pggen.MigrationFile(
+			filepath.Join(*out, *name+".down.sql"),
+			"Down migration generated by pg_gen migrate-diff, from ["+*to+"] to ["+*from+"]",
+			pggen.DiffSchemas(toModel, fromModel)),
+	}
+
+	if strIsEmpty(*out) {
+		pggen.PrintFiles(os.Stdout, files)
+		return nil
+	}
+
+	if err := os.MkdirAll(*out, 0777); err != nil {
+		return fmt.Errorf("failed to create directory [%s], got error [%s]", *out, err.Error())
+	}
+
+	for _, file := range files {
+		if _, err := os.Stat(file.Path); err == nil {
+			return fmt.Errorf("migration file [%s] already exists", file.Path)
+		}
+	}
+
+	for _, file := range files {
+		if err := os.WriteFile(file.Path, file.Content, 0666); err != nil {
+			return fmt.Errorf("failed to write file [%s], got error [%s]", file.Path, err.Error())
+		}
+
+		log.Printf("- Generated [%s]\n", file.Path)
+	}
+
+	return nil
+}
+
+func runInit(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("init", flag.ExitOnError)
+	dsn := flags.String("dsn", "", "The PostgreSQL database connection string")
+	out := flags.String("out", "pg_gen.yaml", "The config YAML or JSON file path to be written")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if strIsEmpty(*dsn) {
+		return errors.New("-dsn must be present and not be blank")
+	}
+
+	if _, err := os.Stat(*out); err == nil {
+		return fmt.Errorf("file [%s] already exists", *out)
+	}
+
+	pgGen, err := pggen.NewPgCodeGenerator(&pggen.Config{DSN: *dsn})
+	if err != nil {
+		return err
+	}
+	defer pgGen.Close()
+
+	schemaNames, err := pgGen.DatabaseSchemaNames(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(schemaNames) == 0 {
+		return errors.New("no schemas found in the database")
+	}
+
+	config := starterConfig(*dsn, schemaNames)
+
+	var content []byte
+	switch filepath.Ext(*out) {
+	case ".json":
+		content, err = json.MarshalIndent(config, "", "  ")
+		if err != nil {
+			return err
+		}
+
+	case ".yaml", ".yml":
+		content = starterConfigYAML(config, schemaNames)
+
+	default:
+		return errors.New("-out must point to a JSON or YAML file")
+	}
+
+	if err := os.WriteFile(*out, content, 0666); err != nil {
+		return fmt.Errorf("failed to write file [%s], got error [%s]", *out, err.Error())
+	}
+
+	log.Printf("- Generated [%s] for schemas [%s]\n", *out, strings.Join(schemaNames, ", "))
+
+	return nil
+}
+
+func goPackageName(schemaName string) string {
+	var sb strings.Builder
+	for _, char := range strings.ToLower(schemaName) {
+		if unicode.IsLetter(char) || (sb.Len() > 0 && unicode.IsDigit(char)) {
+			sb.WriteRune(char)
+		}
+	}
+
+	if sb.Len() == 0 {
+		return "gen"
+	}
+
+	return sb.String()
+}
+
+// initConfig is the starter config written by init, holding only the
+// required keys so the optional ones keep their defaults
+type initConfig struct {
+	DSN     string                      `json:"dsn"`
+	Schemas map[string]initConfigSchema `json:"schemas"`
+}
+
+type initConfigSchema struct {
+	GO initConfigSchemaGO `json:"go"`
+}
+
+type initConfigSchemaGO struct {
+	Dest    string `json:"dest"`
+	Package string `json:"package"`
+}
+
+func starterConfig(dsn string, schemaNames []string) *initConfig {
+	config := &initConfig{
+		DSN:     dsn,
+		Schemas: make(map[string]initConfigSchema, len(schemaNames)),
+	}
+
+	for _, schemaName := range schemaNames {
+		schemaGo := initConfigSchemaGO{
+			Dest:    "./gen",
+			Package: "gen",
+		}
+
+		if len(schemaNames) > 1 {
+			schemaGo.Package = goPackageName(schemaName)
+			schemaGo.Dest = "./gen/" + schemaGo.Package
+		}
+
+		config.Schemas[schemaName] = initConfigSchema{
+			GO: schemaGo,
+		}
+	}
+
+	return config
+}
+
+func starterConfigYAML(config *initConfig, schemaNames []string) []byte {
+	var sb strings.Builder
+	sb.WriteString("# The PostgreSQL database connection string\n")
+	sb.WriteString("dsn: ")
+	sb.WriteString(strconv.Quote(config.DSN))
+	sb.WriteString("\n\n# The database schemas that will be introspected for code generation\n")
+	sb.WriteString("schemas:\n")
+
+	for _, schemaName := range schemaNames {
+		schema := config.Schemas[schemaName]
+
+		sb.WriteString("  ")
+		sb.WriteString(strconv.Quote(schemaName))
+		sb.WriteString(":\n")
+		sb.WriteString("    # If views should be included in code generation (Optional, default=false)\n")
+		sb.WriteString("    include_views: false\n")
+		sb.WriteString("    # If functions and procedures should be included in code generation (Optional, default=false)\n")
+		sb.WriteString("    include_functions: false\n")
+		sb.WriteString("    # Golang code generation specific stuff\n")
+		sb.WriteString("    go:\n")
+		sb.WriteString("      # The destination folder\n")
+		sb.WriteString("      dest: ")
+		sb.WriteString(strconv.Quote(schema.GO.Dest))
+		sb.WriteString("\n      # The generated code package name\n")
+		sb.WriteString("      package: ")
+		sb.WriteString(strconv.Quote(schema.GO.Package))
+		sb.WriteString("\n      # If the generated entities must include JSON tags (Optional, default=false)\n")
+		sb.WriteString("      emit_json_tags: false\n")
+	}
+
+	return []byte(sb.String())
+}
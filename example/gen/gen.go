@@ -3,10 +3,132 @@ package gen
 
 import (
 	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
 	"strconv"
 	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+var (
+	ErrNotFound            = errors.New("not found")
+	ErrUniqueViolation     = errors.New("unique violation")
+	ErrForeignKeyViolation = errors.New("foreign key violation")
+	ErrCheckViolation      = errors.New("check violation")
+	ErrNotNullViolation    = errors.New("not null violation")
 )
 
+// Constraint is the name of a database constraint, it can be used as a target
+// of errors.Is to match a DatabaseError raised by that specific constraint
+type Constraint string
+
+func (c Constraint) Error() string {
+	return string(c)
+}
+
+// DatabaseError is a database error mapped from its SQLSTATE code, Kind holds
+// one of the Err* sentinel errors and Err the original driver error
+type DatabaseError struct {
+	Kind       error
+	Constraint string
+	Table      string
+	Column     string
+	Err        error
+}
+
+func (de *DatabaseError) Error() string {
+	var sb strings.Builder
+	sb.WriteString(de.Kind.Error())
+
+	if de.Constraint != "" {
+		sb.WriteString(" on constraint [")
+		sb.WriteString(de.Constraint)
+		sb.WriteString("]")
+	}
+
+	if de.Table != "" {
+		sb.WriteString(" of table [")
+		sb.WriteString(de.Table)
+		sb.WriteString("]")
+	}
+
+	if de.Column != "" {
+		sb.WriteString(" for column [")
+		sb.WriteString(de.Column)
+		sb.WriteString("]")
+	}
+
+	if de.Err != nil {
+		sb.WriteString(": ")
+		sb.WriteString(de.Err.Error())
+	}
+
+	return sb.String()
+}
+
+func (de *DatabaseError) Unwrap() []error {
+	return []error{de.Kind, de.Err}
+}
+
+func (de *DatabaseError) Is(target error) bool {
+	constraint, ok := target.(Constraint)
+	if !ok {
+		return false
+	}
+
+	return de.Constraint != "" && string(constraint) == de.Constraint
+}
+
+// mapRowError maps the error of a query expected to return a single row,
+// where no row is ErrNotFound
+func mapRowError(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return &DatabaseError{Kind: ErrNotFound, Err: err}
+	}
+
+	return mapError(err)
+}
+
+func mapError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var databaseErr *DatabaseError
+	if errors.As(err, &databaseErr) {
+		return err
+	}
+
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return err
+	}
+
+	var kind error
+	switch pgErr.Code {
+	case "23505":
+		kind = ErrUniqueViolation
+	case "23503":
+		kind = ErrForeignKeyViolation
+	case "23514":
+		kind = ErrCheckViolation
+	case "23502":
+		kind = ErrNotNullViolation
+	default:
+		return err
+	}
+
+	return &DatabaseError{
+		Kind:       kind,
+		Constraint: pgErr.ConstraintName,
+		Table:      pgErr.TableName,
+		Column:     pgErr.ColumnName,
+		Err:        err,
+	}
+}
+
 type Filter struct {
 	Column  string
 	Operand string
@@ -99,7 +221,7 @@ func (so *SelectOptions) toOrderByPart() string {
 			queryBuilder.WriteString(" ")
 			queryBuilder.WriteString(direction.Direction)
 
-			if i < (len(so.Where) - 1) {
+			if i < (len(so.OrderBy) - 1) {
 				queryBuilder.WriteString(", ")
 			}
 		}
@@ -126,6 +248,69 @@ func (so *SelectOptions) toLimitOffsetPart() string {
 	return queryBuilder.String()
 }
 
+func withFilters(opts *SelectOptions, filters ...Filter) *SelectOptions {
+	var filteredOpts SelectOptions
+	if opts != nil {
+		filteredOpts = *opts
+	}
+
+	filteredOpts.Where = append(append([]Filter{}, filters...), filteredOpts.Where...)
+
+	return &filteredOpts
+}
+
+func relationKey(values ...any) (string, bool) {
+	var sb strings.Builder
+
+	for i, value := range values {
+		reflectValue := reflect.ValueOf(value)
+		for reflectValue.Kind() == reflect.Pointer {
+			if reflectValue.IsNil() {
+				return "", false
+			}
+
+			reflectValue = reflectValue.Elem()
+		}
+
+		if !reflectValue.IsValid() {
+			return "", false
+		}
+
+		if i > 0 {
+			sb.WriteString("\x00")
+		}
+
+		sb.WriteString(fmt.Sprint(reflectValue.Interface()))
+	}
+
+	return sb.String(), true
+}
+
+type nullableScanner[T any] struct {
+	dest  *T
+	valid *bool
+}
+
+func (ns nullableScanner[T]) Scan(src any) error {
+	var value sql.Null[T]
+	if err := value.Scan(src); err != nil {
+		return err
+	}
+
+	if value.Valid {
+		*ns.dest = value.V
+		*ns.valid = true
+	}
+
+	return nil
+}
+
+// scanNullable scans a column that may be NULL due to an outer join into dest,
+// flagging valid when a value was found
+func scanNullable[T any](dest *T, valid *bool) sql.Scanner {
+	return nullableScanner[T]{dest: dest, valid: valid}
+}
+
 type UpdateOptions struct {
 	Where []Filter
 }
@@ -142,15 +327,22 @@ func OrderBy(directions ...Direction) []Direction {
 	return directions
 }
 
+// Transaction runs fn in a transaction, which is committed when fn succeeds
+// and rolled back otherwise. The error of fn is returned, joined with the
+// error of the rollback when it also fails
 func Transaction(db *sql.DB, fn func(tx *sql.Tx) error) error {
 	tx, err := db.Begin()
 	if err != nil {
-		return err
+		return mapError(err)
 	}
 
 	if err := fn(tx); err != nil {
-		return tx.Rollback()
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return errors.Join(mapError(err), rollbackErr)
+		}
+
+		return mapError(err)
 	}
 
-	return tx.Commit()
+	return mapError(tx.Commit())
 }
@@ -12,11 +12,15 @@ import (
 type Projects struct {
 	Id          uuid.UUID  `json:"id"`
 	CreatedAt   *time.Time `json:"created_at"`
-	Tier        any        `json:"tier"`
 	Name        string     `json:"name"`
 	Description *string    `json:"description"`
+	Tier        any        `json:"tier"`
 }
 
+const (
+	ProjectsPkeyConstraint Constraint = "projects_pkey"
+)
+
 func (self *Projects) Count(ctx context.Context, db *sql.DB, opts *SelectOptions) (uint, error) {
 	query := `SELECT count(*) FROM "projects"`
 
@@ -34,19 +38,19 @@ func (self *Projects) Count(ctx context.Context, db *sql.DB, opts *SelectOptions
 
 	row := db.QueryRowContext(ctx, query, values...)
 	if row.Err() != nil {
-		return 0, row.Err()
+		return 0, mapError(row.Err())
 	}
 
 	var count uint
 	if err := row.Scan(&count); err != nil {
-		return 0, err
+		return 0, mapError(err)
 	}
 
 	return count, nil
 }
 
 func (self *Projects) Select(ctx context.Context, db *sql.DB, opts *SelectOptions) (*SelectResult[Projects], error) {
-	query := `SELECT "id", "created_at", "tier", "name", "description" FROM "projects"`
+	query := `SELECT "id", "created_at", "name", "description", "tier" FROM "projects"`
 
 	var values []any
 	if opts != nil {
@@ -70,12 +74,12 @@ func (self *Projects) Select(ctx context.Context, db *sql.DB, opts *SelectOption
 
 	total, err := self.Count(ctx, db, opts)
 	if err != nil {
-		return nil, err
+		return nil, mapError(err)
 	}
 
 	rows, err := db.QueryContext(ctx, query, values...)
 	if err != nil {
-		return nil, err
+		return nil, mapError(err)
 	}
 	defer rows.Close()
 
@@ -87,8 +91,8 @@ func (self *Projects) Select(ctx context.Context, db *sql.DB, opts *SelectOption
 
 	for rows.Next() {
 		var entity Projects
-		if err := rows.Scan(&entity.Id, &entity.CreatedAt, &entity.Tier, &entity.Name, &entity.Description); err != nil {
-			return nil, err
+		if err := rows.Scan(&entity.Id, &entity.CreatedAt, &entity.Name, &entity.Description, &entity.Tier); err != nil {
+			return nil, mapError(err)
 		}
 
 		result.Rows = append(result.Rows, entity)
@@ -105,10 +109,10 @@ func (self *Projects) Insert(ctx context.Context, db *sql.DB, values Projects) e
 }
 
 func (self *Projects) InsertTx(ctx context.Context, tx *sql.Tx, values Projects) error {
-	const query = `INSERT INTO "projects" ("id", "created_at", "tier", "name", "description") VALUES ($1::UUID, $2::TIMESTAMP, $3::PROJECT_TIER, $4::VARCHAR, $5::VARCHAR)`
+	const query = `INSERT INTO "projects" ("id", "created_at", "name", "description", "tier") VALUES ($1::UUID, $2::TIMESTAMP, $3::VARCHAR, $4::VARCHAR, $5::PROJECT_TIER)`
 
-	if _, err := tx.ExecContext(ctx, query, values.Id, values.CreatedAt, values.Tier, values.Name, values.Description); err != nil {
-		return err
+	if _, err := tx.ExecContext(ctx, query, values.Id, values.CreatedAt, values.Name, values.Description, values.Tier); err != nil {
+		return mapError(err)
 	}
 
 	return nil
@@ -121,9 +125,9 @@ func (self *Projects) Update(ctx context.Context, db *sql.DB, values Projects, o
 }
 
 func (self *Projects) UpdateTx(ctx context.Context, tx *sql.Tx, values Projects, opts *UpdateOptions) error {
-	query := `UPDATE "projects" SET "created_at" = $2::TIMESTAMP, "tier" = $3::PROJECT_TIER, "name" = $4::VARCHAR, "description" = $5::VARCHAR`
+	query := `UPDATE "projects" SET "created_at" = $2::TIMESTAMP, "name" = $3::VARCHAR, "description" = $4::VARCHAR, "tier" = $5::PROJECT_TIER`
 
-	queryValues := []any{values.Id, values.CreatedAt, values.Tier, values.Name, values.Description}
+	queryValues := []any{values.Id, values.CreatedAt, values.Name, values.Description, values.Tier}
 	if opts != nil {
 		filterPart, v := filtersToQueryPart(opts.Where)
 		if filterPart != "" {
@@ -136,7 +140,7 @@ func (self *Projects) UpdateTx(ctx context.Context, tx *sql.Tx, values Projects,
 	}
 
 	if _, err := tx.ExecContext(ctx, query, queryValues...); err != nil {
-		return err
+		return mapError(err)
 	}
 
 	return nil
@@ -164,7 +168,7 @@ func (self *Projects) DeleteTx(ctx context.Context, tx *sql.Tx, opts *DeleteOpti
 	}
 
 	if _, err := tx.ExecContext(ctx, query, values...); err != nil {
-		return err
+		return mapError(err)
 	}
 
 	return nil
@@ -31,12 +31,12 @@ func (self *VFreeProjects) Count(ctx context.Context, db *sql.DB, opts *SelectOp
 
 	row := db.QueryRowContext(ctx, query, values...)
 	if row.Err() != nil {
-		return 0, row.Err()
+		return 0, mapError(row.Err())
 	}
 
 	var count uint
 	if err := row.Scan(&count); err != nil {
-		return 0, err
+		return 0, mapError(err)
 	}
 
 	return count, nil
@@ -67,12 +67,12 @@ func (self *VFreeProjects) Select(ctx context.Context, db *sql.DB, opts *SelectO
 
 	total, err := self.Count(ctx, db, opts)
 	if err != nil {
-		return nil, err
+		return nil, mapError(err)
 	}
 
 	rows, err := db.QueryContext(ctx, query, values...)
 	if err != nil {
-		return nil, err
+		return nil, mapError(err)
 	}
 	defer rows.Close()
 
@@ -85,7 +85,7 @@ func (self *VFreeProjects) Select(ctx context.Context, db *sql.DB, opts *SelectO
 	for rows.Next() {
 		var entity VFreeProjects
 		if err := rows.Scan(&entity.Id, &entity.Name, &entity.Desc); err != nil {
-			return nil, err
+			return nil, mapError(err)
 		}
 
 		result.Rows = append(result.Rows, entity)
@@ -1,34 +1,59 @@
 package main
 
 import (
-	"flag"
+	"context"
+	"fmt"
 	"log"
+	"os"
+	"strings"
 )
 
-func run() error {
-	configPath := flag.String("config", "", "The config JSON file path")
-	flag.Parse()
+const _usage = `Usage: pg_gen <command> [flags]
 
-	config, err := LoadConfigFromFile(*configPath)
-	if err != nil {
-		return err
-	}
+Commands:
+  generate  Generate code for the configured database schemas (default)
+  check     Exit with a non zero code if the generated files are stale
+  diff      Show an unified diff of the changes generate would make
+  inspect   Print the introspected database model as JSON
+  drift     Exit with a non zero code if the database drifted from a schema file
+  migrate-diff
+            Write the up and down SQL migrating one model to another
+  init      Write a starter config file by probing the database schemas
 
-	if err := config.Validate(); err != nil {
-		return err
-	}
+Run "pg_gen <command> -h" for the command flags.
+`
 
-	pgGen, err := NewPgCodeGenerator(config)
-	if err != nil {
-		return err
+func run(ctx context.Context, args []string) error {
+	command := "generate"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		command, args = args[0], args[1:]
 	}
-	defer pgGen.Close()
 
-	return pgGen.Generate()
+	switch command {
+	case "generate":
+		return runGenerate(ctx, args)
+	case "check":
+		return runCheck(ctx, args)
+	case "diff":
+		return runDiff(ctx, args)
+	case "inspect":
+		return runInspect(ctx, args)
+	case "drift":
+		return runDrift(ctx, args)
+	case "migrate-diff":
+		return runMigrateDiff(ctx, args)
+	case "init":
+		return runInit(ctx, args)
+	case "help":
+		fmt.Print(_usage)
+		return nil
+	default:
+		return fmt.Errorf("unknown command [%s]\n\n%s", command, _usage)
+	}
 }
 
 func main() {
-	if err := run(); err != nil {
+	if err := run(context.Background(), os.Args[1:]); err != nil {
 		log.Fatal(err)
 	}
 }
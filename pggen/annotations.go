@@ -0,0 +1,119 @@
+package pggen
+
+import (
+	"fmt"
+	"log"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+const (
+	_ignoreAnnotation  = "ignore"
+	_typeAnnotation    = "type"
+	_notNullAnnotation = "not_null"
+)
+
+var _annotationRegexp = regexp.MustCompile(`@pg_gen:([a-z_]+)(?:=(\S+))?`)
+
+// parseAnnotations splits a comment in its text and its @pg_gen annotations,
+// such as @pg_gen:ignore or @pg_gen:type=int64
+func parseAnnotations(comment string) (string, map[string]string) {
+	annotations := make(map[string]string)
+	for _, match := range _annotationRegexp.FindAllStringSubmatch(comment, -1) {
+		annotations[match[1]] = match[2]
+	}
+
+	lines := strings.Split(_annotationRegexp.ReplaceAllString(comment, ""), "\n")
+	for i := range lines {
+		lines[i] = strings.TrimSpace(lines[i])
+	}
+
+	return strings.TrimSpace(strings.Join(lines, "\n")), annotations
+}
+
+// notNullAnnotation reports if the output is annotated as never null, by a
+// @pg_gen:not_null annotation without value, which covers every output, or
+// listing it, such as @pg_gen:not_null=id,name
+func notNullAnnotation(annotations map[string]string, output string) bool {
+	value, ok := annotations[_notNullAnnotation]
+	if !ok {
+		return false
+	}
+
+	return strIsEmpty(value) || slices.Contains(strings.Split(value, ","), output)
+}
+
+// goTypeAnnotation returns the Go type and the import path of a type
+// annotation, types of other packages are given with their import path, such
+// as github.com/shopspring/decimal.Decimal
+func goTypeAnnotation(value string) (string, string) {
+	slash := strings.LastIndex(value, "/")
+	dot := strings.LastIndex(value, ".")
+	if slash < 0 || dot < slash {
+		return value, ""
+	}
+
+	return value[slash+1:], value[:dot]
+}
+
+// applyAnnotations returns a copy of the model without the tables and columns
+// annotated with @pg_gen:ignore, with the Go types of the @pg_gen:type
+// annotations and with the annotations removed from the comments
+func applyAnnotations(model PgSchema) (PgSchema, error) {
+	tables := make([]PgTable, 0, len(model.Tables))
+	for _, table := range model.Tables {
+		comment, annotations := parseAnnotations(table.Comment)
+		for _, name := range slices.Sorted(maps.Keys(annotations)) {
+			if name != _ignoreAnnotation {
+				return PgSchema{}, fmt.Errorf("unknown annotation [@pg_gen:%s] in the comment of %s [%s]", name, table.Kind, table.Name)
+			}
+		}
+
+		if _, ok := annotations[_ignoreAnnotation]; ok {
+			log.Printf("- Ignored %s [%s], annotated with @pg_gen:ignore\n", table.Kind, table.Name)
+			continue
+		}
+
+		table.Comment = comment
+		columns := make([]PgColumn, 0, len(table.Columns))
+		for _, column := range table.Columns {
+			comment, annotations := parseAnnotations(column.Comment)
+			column.Comment = comment
+
+			for _, name := range slices.Sorted(maps.Keys(annotations)) {
+				switch name {
+				case _ignoreAnnotation:
+					if column.IsPrimaryKey {
+						return PgSchema{}, fmt.Errorf("column [%s] of %s [%s] is in the primary key and can not be ignored", column.Name, table.Kind, table.Name)
+					}
+
+				case _typeAnnotation:
+					if strIsEmpty(annotations[name]) {
+						return PgSchema{}, fmt.Errorf("annotation [@pg_gen:type] of column [%s] of %s [%s] must have a type, such as @pg_gen:type=int64", column.Name, table.Kind, table.Name)
+					}
+
+					column.GoDataType, column.goImport = goTypeAnnotation(annotations[name])
+
+				default:
+					return PgSchema{}, fmt.Errorf("unknown annotation [@pg_gen:%s] in the comment of column [%s] of %s [%s]", name, column.Name, table.Kind, table.Name)
+				}
+			}
+
+			if _, ok := annotations[_ignoreAnnotation]; ok {
+				log.Printf("- Ignored column [%s] of %s [%s], annotated with @pg_gen:ignore\n", column.Name, table.Kind, table.Name)
+				continue
+			}
+
+			columns = append(columns, column)
+		}
+
+		table.Columns = columns
+		tables = append(tables, table)
+	}
+
+	model.Tables = tables
+
+	return model, nil
+}
@@ -0,0 +1,451 @@
+package pggen
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/token"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-yaml"
+)
+
+type ConfigTemplate struct {
+	Kind   string `json:"kind" yaml:"kind"`
+	Path   string `json:"path" yaml:"path"`
+	Output string `json:"output" yaml:"output"`
+}
+
+func (ct *ConfigTemplate) Validate(name string, index int) error {
+	if !slices.Contains([]string{_table, _view, _common}, ct.Kind) {
+		return fmt.Errorf("$.schemas.%s.go.templates[%d].kind must be one of [table, view, common]", name, index)
+	}
+
+	if strIsEmpty(ct.Path) {
+		return fmt.Errorf("$.schemas.%s.go.templates[%d].path must be present and not be blank", name, index)
+	}
+
+	return nil
+}
+
+type ConfigPlugin struct {
+	Command           string         `json:"command" yaml:"command"`
+	Args              []string       `json:"args" yaml:"args"`
+	Dest              string         `json:"dest" yaml:"dest"`
+	Options           map[string]any `json:"options" yaml:"options"`
+	Timeout           string         `json:"timeout" yaml:"timeout"`
+	KeepOrphanedFiles bool           `json:"keep_orphaned_files" yaml:"keep_orphaned_files"`
+}
+
+// _defaultPluginTimeout is how long a plugin can run when it has no timeout
+const _defaultPluginTimeout = time.Minute
+
+// timeout returns how long the plugin can run before it is killed
+func (cp *ConfigPlugin) timeout() time.Duration {
+	timeout, err := time.ParseDuration(cp.Timeout)
+	if err != nil {
+		return _defaultPluginTimeout
+	}
+
+	return timeout
+}
+
+func (cp *ConfigPlugin) Validate(index int) error {
+	if strIsEmpty(cp.Command) {
+		return fmt.Errorf("$.plugins[%d].command must be present and not be blank", index)
+	}
+
+	if strIsEmpty(cp.Dest) {
+		return fmt.Errorf("$.plugins[%d].dest must be present and not be blank", index)
+	}
+
+	if !strIsEmpty(cp.Timeout) {
+		if timeout, err := time.ParseDuration(cp.Timeout); err != nil || timeout <= 0 {
+			return fmt.Errorf("$.plugins[%d].timeout must be a positive duration, such as 30s or 2m", index)
+		}
+	}
+
+	return nil
+}
+
+const (
+	_tagCaseSnake          = "snake"
+	_tagCaseCamel          = "camel"
+	_tagCaseLowerCamel     = "lower_camel"
+	_tagCaseKebab          = "kebab"
+	_tagCaseScreamingSnake = "screaming_snake"
+	_tagCaseNone           = "none"
+
+	_omitEmptyNever    = "never"
+	_omitEmptyNullable = "nullable"
+	_omitEmptyAlways   = "always"
+)
+
+// ConfigTag is a struct tag of the generated entities, Columns overrides the
+// tag value of columns by table
+type ConfigTag struct {
+	Name        string                       `json:"name" yaml:"name"`
+	Case        string                       `json:"case" yaml:"case"`
+	OmitEmpty   string                       `json:"omitempty" yaml:"omitempty"`
+	OnlyColumns bool                         `json:"only_columns" yaml:"only_columns"`
+	Columns     map[string]map[string]string `json:"columns" yaml:"columns"`
+}
+
+func (ct *ConfigTag) Validate(name string, index int) error {
+	if strIsEmpty(ct.Name) || strings.ContainsAny(ct.Name, " \t\n:\"`") {
+		return fmt.Errorf("$.schemas.%s.go.tags[%d].name must be present and be a struct tag key, such as db or yaml", name, index)
+	}
+
+	if !strIsEmpty(ct.Case) && !slices.Contains([]string{
+		_tagCaseSnake, _tagCaseCamel, _tagCaseLowerCamel, _tagCaseKebab, _tagCaseScreamingSnake, _tagCaseNone,
+	}, ct.Case) {
+		return fmt.Errorf("$.schemas.%s.go.tags[%d].case must be one of [snake, camel, lower_camel, kebab, screaming_snake, none]", name, index)
+	}
+
+	if !strIsEmpty(ct.OmitEmpty) && !slices.Contains([]string{_omitEmptyNever, _omitEmptyNullable, _omitEmptyAlways}, ct.OmitEmpty) {
+		return fmt.Errorf("$.schemas.%s.go.tags[%d].omitempty must be one of [never, nullable, always]", name, index)
+	}
+
+	for tableName, columns := range ct.Columns {
+		for columnName, value := range columns {
+			if strings.ContainsAny(value, "\"`\n") {
+				return fmt.Errorf("$.schemas.%s.go.tags[%d].columns.%s.%s must not have quotes or line breaks", name, index, tableName, columnName)
+			}
+		}
+	}
+
+	return nil
+}
+
+type ConfigSchemaGO struct {
+	Dest              string           `json:"dest" yaml:"dest"`
+	Package           string           `json:"package" yaml:"package"`
+	EmitJsonTags      bool             `json:"emit_json_tags" yaml:"emit_json_tags"`
+	EmitValidation    bool             `json:"emit_validation" yaml:"emit_validation"`
+	Tags              []ConfigTag      `json:"tags" yaml:"tags"`
+	KeepOrphanedFiles bool             `json:"keep_orphaned_files" yaml:"keep_orphaned_files"`
+	Templates         []ConfigTemplate `json:"templates" yaml:"templates"`
+}
+
+// structTags returns the tags of the entities, emit_json_tags adds a snake
+// case json tag when the tags do not have one
+func (csg *ConfigSchemaGO) structTags() []ConfigTag {
+	tags := slices.Clone(csg.Tags)
+	if csg.EmitJsonTags && !slices.ContainsFunc(tags, func(tag ConfigTag) bool { return tag.Name == "json" }) {
+		tags = slices.Insert(tags, 0, ConfigTag{Name: "json"})
+	}
+
+	return tags
+}
+
+func (csg *ConfigSchemaGO) Validate(name string) error {
+	if strIsEmpty(csg.Dest) {
+		return fmt.Errorf("$.schemas.%s.go.dest must be present and not be blank", name)
+	}
+
+	if strIsEmpty(csg.Package) {
+		return fmt.Errorf("$.schemas.%s.go.package must be present and not be blank", name)
+	}
+
+	for i, template := range csg.Templates {
+		if err := template.Validate(name, i); err != nil {
+			return err
+		}
+	}
+
+	for i, tag := range csg.Tags {
+		if err := tag.Validate(name, i); err != nil {
+			return err
+		}
+
+		if slices.ContainsFunc(csg.Tags[:i], func(other ConfigTag) bool { return other.Name == tag.Name }) {
+			return fmt.Errorf("$.schemas.%s.go.tags[%d].name [%s] is used by another tag", name, i, tag.Name)
+		}
+	}
+
+	return nil
+}
+
+const (
+	_nullableAsNull     = "null"
+	_nullableAsOptional = "optional"
+)
+
+type ConfigSchemaTypeScript struct {
+	Dest              string `json:"dest" yaml:"dest"`
+	Nullable          string `json:"nullable" yaml:"nullable"`
+	KeepOrphanedFiles bool   `json:"keep_orphaned_files" yaml:"keep_orphaned_files"`
+}
+
+func (cst *ConfigSchemaTypeScript) Validate(name string) error {
+	if strIsEmpty(cst.Dest) {
+		return fmt.Errorf("$.schemas.%s.typescript.dest must be present and not be blank", name)
+	}
+
+	if !strIsEmpty(cst.Nullable) && cst.Nullable != _nullableAsNull && cst.Nullable != _nullableAsOptional {
+		return fmt.Errorf("$.schemas.%s.typescript.nullable must be one of [null, optional]", name)
+	}
+
+	return nil
+}
+
+type ConfigSchemaJsonSchema struct {
+	Dest              string `json:"dest" yaml:"dest"`
+	KeepOrphanedFiles bool   `json:"keep_orphaned_files" yaml:"keep_orphaned_files"`
+}
+
+func (cjs *ConfigSchemaJsonSchema) Validate(name string) error {
+	if strIsEmpty(cjs.Dest) {
+		return fmt.Errorf("$.schemas.%s.json_schema.dest must be present and not be blank", name)
+	}
+
+	return nil
+}
+
+type ConfigSchemaProto struct {
+	Dest              string `json:"dest" yaml:"dest"`
+	Package           string `json:"package" yaml:"package"`
+	GoPackage         string `json:"go_package" yaml:"go_package"`
+	KeepOrphanedFiles bool   `json:"keep_orphaned_files" yaml:"keep_orphaned_files"`
+}
+
+func (csp *ConfigSchemaProto) Validate(name string) error {
+	if strIsEmpty(csp.Dest) {
+		return fmt.Errorf("$.schemas.%s.proto.dest must be present and not be blank", name)
+	}
+
+	if strIsEmpty(csp.Package) {
+		return fmt.Errorf("$.schemas.%s.proto.package must be present and not be blank", name)
+	}
+
+	return nil
+}
+
+type ConfigSchemaPython struct {
+	Dest              string `json:"dest" yaml:"dest"`
+	Style             string `json:"style" yaml:"style"`
+	KeepOrphanedFiles bool   `json:"keep_orphaned_files" yaml:"keep_orphaned_files"`
+}
+
+func (csp *ConfigSchemaPython) Validate(name string) error {
+	if strIsEmpty(csp.Dest) {
+		return fmt.Errorf("$.schemas.%s.python.dest must be present and not be blank", name)
+	}
+
+	if !strIsEmpty(csp.Style) && csp.Style != _pythonDataclass && csp.Style != _pythonPydantic {
+		return fmt.Errorf("$.schemas.%s.python.style must be one of [dataclass, pydantic]", name)
+	}
+
+	return nil
+}
+
+type ConfigSchemaDocs struct {
+	Dest              string `json:"dest" yaml:"dest"`
+	KeepOrphanedFiles bool   `json:"keep_orphaned_files" yaml:"keep_orphaned_files"`
+}
+
+func (csd *ConfigSchemaDocs) Validate(name string) error {
+	if strIsEmpty(csd.Dest) {
+		return fmt.Errorf("$.schemas.%s.docs.dest must be present and not be blank", name)
+	}
+
+	return nil
+}
+
+// ConfigNaming changes how the Go names of the tables and columns are derived,
+// Tables and Columns rename them explicitly
+type ConfigNaming struct {
+	Initialisms   []string                     `json:"initialisms" yaml:"initialisms"`
+	Singular      bool                         `json:"singular" yaml:"singular"`
+	StripPrefixes []string                     `json:"strip_prefixes" yaml:"strip_prefixes"`
+	Tables        map[string]string            `json:"tables" yaml:"tables"`
+	Columns       map[string]map[string]string `json:"columns" yaml:"columns"`
+}
+
+func (cn *ConfigNaming) Validate(name string) error {
+	for i, initialism := range cn.Initialisms {
+		if strIsEmpty(initialism) || !token.IsIdentifier(initialism) {
+			return fmt.Errorf("$.schemas.%s.naming.initialisms[%d] must be a word, such as ID or URL", name, i)
+		}
+	}
+
+	for i, prefix := range cn.StripPrefixes {
+		if strIsEmpty(prefix) {
+			return fmt.Errorf("$.schemas.%s.naming.strip_prefixes[%d] must not be blank", name, i)
+		}
+	}
+
+	for tableName, goName := range cn.Tables {
+		if !token.IsIdentifier(goName) || !token.IsExported(goName) {
+			return fmt.Errorf("$.schemas.%s.naming.tables.%s must be an exported Go identifier, got [%s]", name, tableName, goName)
+		}
+	}
+
+	for tableName, columns := range cn.Columns {
+		for columnName, goName := range columns {
+			if !token.IsIdentifier(goName) || !token.IsExported(goName) {
+				return fmt.Errorf("$.schemas.%s.naming.columns.%s.%s must be an exported Go identifier, got [%s]", name, tableName, columnName, goName)
+			}
+		}
+	}
+
+	return nil
+}
+
+type ConfigSchema struct {
+	IncludeViews     bool                    `json:"include_views" yaml:"include_views"`
+	IncludeFunctions bool                    `json:"include_functions" yaml:"include_functions"`
+	Ignore           []string                `json:"ignore" yaml:"ignore"`
+	Queries          string                  `json:"queries" yaml:"queries"`
+	GO               *ConfigSchemaGO         `json:"go" yaml:"go"`
+	TypeScript       *ConfigSchemaTypeScript `json:"typescript" yaml:"typescript"`
+	JsonSchema       *ConfigSchemaJsonSchema `json:"json_schema" yaml:"json_schema"`
+	Proto            *ConfigSchemaProto      `json:"proto" yaml:"proto"`
+	Python           *ConfigSchemaPython     `json:"python" yaml:"python"`
+	Docs             *ConfigSchemaDocs       `json:"docs" yaml:"docs"`
+	Naming           ConfigNaming            `json:"naming" yaml:"naming"`
+}
+
+func (cs *ConfigSchema) Validate(name string) error {
+	if cs.GO == nil && cs.TypeScript == nil && cs.JsonSchema == nil && cs.Proto == nil && cs.Python == nil && cs.Docs == nil {
+		return fmt.Errorf("$.schemas.%s must have a go, typescript, json_schema, proto, python or docs block", name)
+	}
+
+	if cs.GO != nil {
+		if err := cs.GO.Validate(name); err != nil {
+			return err
+		}
+	}
+
+	if cs.TypeScript != nil {
+		if err := cs.TypeScript.Validate(name); err != nil {
+			return err
+		}
+	}
+
+	if cs.JsonSchema != nil {
+		if err := cs.JsonSchema.Validate(name); err != nil {
+			return err
+		}
+	}
+
+	if cs.Proto != nil {
+		if err := cs.Proto.Validate(name); err != nil {
+			return err
+		}
+	}
+
+	if cs.Python != nil {
+		if err := cs.Python.Validate(name); err != nil {
+			return err
+		}
+	}
+
+	if cs.Docs != nil {
+		if err := cs.Docs.Validate(name); err != nil {
+			return err
+		}
+	}
+
+	if err := cs.Naming.Validate(name); err != nil {
+		return err
+	}
+
+	if cs.GO == nil && cs.IncludeFunctions {
+		return fmt.Errorf("$.schemas.%s.include_functions requires $.schemas.%s.go, functions are only generated for Go", name, name)
+	}
+
+	if cs.GO == nil && !strIsEmpty(cs.Queries) {
+		return fmt.Errorf("$.schemas.%s.queries requires $.schemas.%s.go, queries are only generated for Go", name, name)
+	}
+
+	return nil
+}
+
+func (cs *ConfigSchema) ShouldIgnore(objectName string) bool {
+	if len(cs.Ignore) == 0 {
+		return false
+	}
+
+	return slices.ContainsFunc(cs.Ignore, func(str string) bool {
+		return strings.ToLower(objectName) == strings.ToLower(str)
+	})
+}
+
+type Config struct {
+	DSN        string                  `json:"dsn" yaml:"dsn"`
+	SchemaFile string                  `json:"schema_file" yaml:"schema_file"`
+	Migrations string                  `json:"migrations" yaml:"migrations"`
+	Schemas    map[string]ConfigSchema `json:"schemas" yaml:"schemas"`
+	Plugins    []ConfigPlugin          `json:"plugins" yaml:"plugins"`
+}
+
+func (c *Config) Validate() error {
+	if strIsEmpty(c.DSN) && strIsEmpty(c.SchemaFile) && strIsEmpty(c.Migrations) {
+		return errors.New("$.dsn, $.schema_file or $.migrations must be present and not be blank")
+	}
+
+	if !strIsEmpty(c.SchemaFile) && !strIsEmpty(c.Migrations) {
+		return errors.New("$.schema_file and $.migrations must not be used together")
+	}
+
+	if len(c.Schemas) == 0 {
+		return errors.New("$.schemas must be present and not be empty")
+	}
+
+	for name, schema := range c.Schemas {
+		if err := schema.Validate(name); err != nil {
+			return err
+		}
+
+		if !strIsEmpty(c.Migrations) && schema.IncludeFunctions {
+			return fmt.Errorf("$.schemas.%s.include_functions must not be used with $.migrations, functions are not read from migrations", name)
+		}
+
+		if !strIsEmpty(c.Migrations) && !strIsEmpty(schema.Queries) {
+			return fmt.Errorf("$.schemas.%s.queries must not be used with $.migrations, describing queries requires a database", name)
+		}
+	}
+
+	for i, plugin := range c.Plugins {
+		if err := plugin.Validate(i); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func LoadConfigFromFile(path string) (*Config, error) {
+	if strIsEmpty(path) {
+		return nil, errors.New("invalid path to config, it must be a non blank, valid YAML or JSON file")
+	}
+
+	ext := filepath.Ext(path)
+	configContent, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file [%s]", path)
+	}
+
+	var config Config
+	switch ext {
+	case ".json":
+		if err := json.Unmarshal(configContent, &config); err != nil {
+			return nil, fmt.Errorf("failed to decode file [%s] as JSON", path)
+		}
+
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(configContent, &config); err != nil {
+			return nil, fmt.Errorf("failed to decode file [%s] as YAML", path)
+		}
+
+	default:
+		return nil, errors.New("filepath must point to a valid JSON or YAML file")
+	}
+
+	return &config, nil
+}
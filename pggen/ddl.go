@@ -0,0 +1,677 @@
+package pggen
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	_identifierToken = 'i'
+	_stringToken     = 's'
+	_numberToken     = 'n'
+	_symbolToken     = 'p'
+)
+
+// _statementModifiers are the keywords kept when labeling a statement in warnings
+var _statementModifiers = []string{
+	"create", "alter", "drop", "or", "replace", "temp", "temporary", "unlogged",
+	"materialized", "unique", "global", "local", "recursive",
+}
+
+var _columnConstraintKeywords = []string{
+	"constraint", "not", "null", "primary", "unique", "references", "check",
+	"default", "generated", "collate", "using",
+}
+
+var _serialTypes = []string{"serial", "serial4", "bigserial", "serial8", "smallserial", "serial2"}
+
+// _sqlTypeNames maps the SQL type names to the internal names Postgres
+// reports for them, other types are reported by their own name
+var _sqlTypeNames = map[string]string{
+	"int":                         "INT4",
+	"integer":                     "INT4",
+	"serial":                      "INT4",
+	"serial4":                     "INT4",
+	"bigint":                      "INT8",
+	"bigserial":                   "INT8",
+	"serial8":                     "INT8",
+	"smallint":                    "INT2",
+	"smallserial":                 "INT2",
+	"serial2":                     "INT2",
+	"boolean":                     "BOOL",
+	"real":                        "FLOAT4",
+	"float":                       "FLOAT8",
+	"double precision":            "FLOAT8",
+	"decimal":                     "NUMERIC",
+	"character varying":           "VARCHAR",
+	"char varying":                "VARCHAR",
+	"character":                   "BPCHAR",
+	"char":                        "BPCHAR",
+	"timestamp without time zone": "TIMESTAMP",
+	"timestamp with time zone":    "TIMESTAMPTZ",
+	"time without time zone":      "TIME",
+	"time with time zone":         "TIMETZ",
+	"bit varying":                 "VARBIT",
+}
+
+type sqlToken struct {
+	Kind   byte
+	Text   string
+	Quoted bool
+	Line   int
+}
+
+func (t sqlToken) isKeyword(keyword string) bool {
+	return t.Kind == _identifierToken && !t.Quoted && t.Text == keyword
+}
+
+func (t sqlToken) isSymbol(symbol string) bool {
+	return t.Kind == _symbolToken && t.Text == symbol
+}
+
+func (t sqlToken) String() string {
+	if t.Kind == 0 {
+		return "end of statement"
+	}
+
+	return t.Text
+}
+
+func tokensText(tokens []sqlToken) string {
+	texts := make([]string, len(tokens))
+	for i, token := range tokens {
+		texts[i] = token.Text
+	}
+
+	return strings.Join(texts, " ")
+}
+
+var (
+	_sqlTextTightBefore = []string{")", ",", "::", ".", "[", "]"}
+	_sqlTextTightAfter  = []string{"(", "::", ".", "["}
+)
+
+func sqlTextSpaced(previous, token sqlToken) bool {
+	if token.Kind == _symbolToken && slices.Contains(_sqlTextTightBefore, token.Text) {
+		return false
+	}
+
+	if previous.Kind == _symbolToken && slices.Contains(_sqlTextTightAfter, previous.Text) {
+		return false
+	}
+
+	return !token.isSymbol("(") || previous.Kind != _identifierToken
+}
+
+// sqlText renders tokens back to SQL, such as column defaults. Spacing and
+// the case of unquoted identifiers are normalized
+func sqlText(tokens []sqlToken) string {
+	var sb strings.Builder
+	for i, token := range tokens {
+		if i > 0 && sqlTextSpaced(tokens[i-1], token) {
+			sb.WriteString(" ")
+		}
+
+		switch {
+		case token.Kind == _stringToken:
+			sb.WriteString(quoteLiteral(token.Text))
+		case token.Quoted:
+			sb.WriteString(quoteIdentifier(token.Text))
+		default:
+			sb.WriteString(token.Text)
+		}
+	}
+
+	return sb.String()
+}
+
+// quotedText reads the text quoted by the character at start, returning the
+// unescaped text and the position after the closing quote
+func quotedText(sql string, start int, backslashEscapes bool) (string, int, bool) {
+	quote := sql[start]
+
+	var sb strings.Builder
+	for i := start + 1; i < len(sql); i++ {
+		switch {
+		case backslashEscapes && sql[i] == '\\' && i+1 < len(sql):
+			sb.WriteByte(sql[i+1])
+			i++
+
+		case sql[i] == quote && i+1 < len(sql) && sql[i+1] == quote:
+			sb.WriteByte(quote)
+			i++
+
+		case sql[i] == quote:
+			return sb.String(), i + 1, true
+
+		default:
+			sb.WriteByte(sql[i])
+		}
+	}
+
+	return "", len(sql), false
+}
+
+func isOperatorChar(char byte) bool {
+	return strings.IndexByte("+-*/<>=~!@#%^&|`?:", char) >= 0
+}
+
+// tokenizeSQL splits the SQL into tokens, unquoted identifiers are lower cased
+// as Postgres does
+func tokenizeSQL(sql string) ([]sqlToken, error) {
+	var tokens []sqlToken
+	line := 1
+
+	for i := 0; i < len(sql); {
+		char := sql[i]
+		start := line
+
+		switch {
+		case char == '\n':
+			line++
+			i++
+
+		case char == ' ' || char == '\t' || char == '\r' || char == '\f':
+			i++
+
+		case strings.HasPrefix(sql[i:], "--"):
+			end := strings.IndexByte(sql[i:], '\n')
+			if end < 0 {
+				end = len(sql) - i
+			}
+
+			i += end
+
+		case strings.HasPrefix(sql[i:], "/*"):
+			end := strings.Index(sql[i+2:], "*/")
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated comment at line [%d]", start)
+			}
+
+			line += strings.Count(sql[i:i+end+4], "\n")
+			i += end + 4
+
+		case char == '\'' || ((char == 'e' || char == 'E') && i+1 < len(sql) && sql[i+1] == '\''):
+			backslashEscapes := char != '\''
+			if backslashEscapes {
+				i++
+			}
+
+			text, end, ok := quotedText(sql, i, backslashEscapes)
+			if !ok {
+				return nil, fmt.Errorf("unterminated string at line [%d]", start)
+			}
+
+			line += strings.Count(sql[i:end], "\n")
+			tokens = append(tokens, sqlToken{Kind: _stringToken, Text: text, Line: start})
+			i = end
+
+		case char == '"':
+			text, end, ok := quotedText(sql, i, false)
+			if !ok {
+				return nil, fmt.Errorf("unterminated quoted identifier at line [%d]", start)
+			}
+
+			line += strings.Count(sql[i:end], "\n")
+			tokens = append(tokens, sqlToken{Kind: _identifierToken, Text: text, Quoted: true, Line: start})
+			i = end
+
+		case char == '$' && i+1 < len(sql) && sql[i+1] >= '0' && sql[i+1] <= '9':
+			end := i + 1
+			for end < len(sql) && sql[end] >= '0' && sql[end] <= '9' {
+				end++
+			}
+
+			tokens = append(tokens, sqlToken{Kind: _symbolToken, Text: sql[i:end], Line: start})
+			i = end
+
+		case char == '$':
+			tagEnd := i + 1
+			for tagEnd < len(sql) && isIdentifierPart(sql[tagEnd]) {
+				tagEnd++
+			}
+
+			if tagEnd >= len(sql) || sql[tagEnd] != '$' {
+				tokens = append(tokens, sqlToken{Kind: _symbolToken, Text: "$", Line: start})
+				i++
+				continue
+			}
+
+			tag := sql[i : tagEnd+1]
+			end := strings.Index(sql[tagEnd+1:], tag)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated dollar quoted string at line [%d]", start)
+			}
+
+			text := sql[tagEnd+1 : tagEnd+1+end]
+			line += strings.Count(sql[i:tagEnd+1+end+len(tag)], "\n")
+			tokens = append(tokens, sqlToken{Kind: _stringToken, Text: text, Line: start})
+			i = tagEnd + 1 + end + len(tag)
+
+		case isIdentifierStart(char) || char >= 0x80:
+			end := i + 1
+			for end < len(sql) && (isIdentifierPart(sql[end]) || sql[end] == '$' || sql[end] >= 0x80) {
+				end++
+			}
+
+			tokens = append(tokens, sqlToken{Kind: _identifierToken, Text: strings.ToLower(sql[i:end]), Line: start})
+			i = end
+
+		case char >= '0' && char <= '9':
+			end := i + 1
+			for end < len(sql) && (isIdentifierPart(sql[end]) || sql[end] == '.') {
+				end++
+			}
+
+			tokens = append(tokens, sqlToken{Kind: _numberToken, Text: sql[i:end], Line: start})
+			i = end
+
+		case isOperatorChar(char):
+			end := i + 1
+			for end < len(sql) && isOperatorChar(sql[end]) {
+				end++
+			}
+
+			tokens = append(tokens, sqlToken{Kind: _symbolToken, Text: sql[i:end], Line: start})
+			i = end
+
+		default:
+			tokens = append(tokens, sqlToken{Kind: _symbolToken, Text: string(char), Line: start})
+			i++
+		}
+	}
+
+	return tokens, nil
+}
+
+type sqlStatement struct {
+	File   string
+	Line   int
+	Tokens []sqlToken
+}
+
+func (s *sqlStatement) location() string {
+	return s.File + ":" + strconv.Itoa(s.Line)
+}
+
+// label returns the leading keywords of the statement, such as CREATE OR
+// REPLACE FUNCTION, used to identify it in warnings
+func (s *sqlStatement) label() string {
+	var words []string
+	for _, token := range s.Tokens {
+		if token.Kind != _identifierToken || token.Quoted {
+			break
+		}
+
+		words = append(words, strings.ToUpper(token.Text))
+		if !slices.Contains(_statementModifiers, token.Text) {
+			break
+		}
+	}
+
+	return strings.Join(words, " ")
+}
+
+func splitStatements(file string, tokens []sqlToken) []sqlStatement {
+	var statements []sqlStatement
+	var current []sqlToken
+
+	flush := func() {
+		if len(current) > 0 {
+			statements = append(statements, sqlStatement{
+				File:   file,
+				Line:   current[0].Line,
+				Tokens: current,
+			})
+		}
+
+		current = nil
+	}
+
+	for _, token := range tokens {
+		if token.isSymbol(";") {
+			flush()
+			continue
+		}
+
+		current = append(current, token)
+	}
+
+	flush()
+
+	return statements
+}
+
+// splitList splits the tokens by the commas outside of parentheses
+func splitList(tokens []sqlToken) [][]sqlToken {
+	var items [][]sqlToken
+	depth, start := 0, 0
+
+	for i, token := range tokens {
+		switch {
+		case token.isSymbol("(") || token.isSymbol("["):
+			depth++
+		case token.isSymbol(")") || token.isSymbol("]"):
+			depth--
+		case token.isSymbol(",") && depth == 0:
+			items = append(items, tokens[start:i])
+			start = i + 1
+		}
+	}
+
+	if start < len(tokens) {
+		items = append(items, tokens[start:])
+	}
+
+	return items
+}
+
+type ddlParser struct {
+	tokens []sqlToken
+	pos    int
+}
+
+func (p *ddlParser) done() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *ddlParser) peek() sqlToken {
+	if p.done() {
+		return sqlToken{}
+	}
+
+	return p.tokens[p.pos]
+}
+
+func (p *ddlParser) next() sqlToken {
+	token := p.peek()
+	if !p.done() {
+		p.pos++
+	}
+
+	return token
+}
+
+func (p *ddlParser) rest() []sqlToken {
+	return p.tokens[p.pos:]
+}
+
+func (p *ddlParser) isKeyword(keywords ...string) bool {
+	for i, keyword := range keywords {
+		if p.pos+i >= len(p.tokens) || !p.tokens[p.pos+i].isKeyword(keyword) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (p *ddlParser) acceptKeyword(keywords ...string) bool {
+	if !p.isKeyword(keywords...) {
+		return false
+	}
+
+	p.pos += len(keywords)
+
+	return true
+}
+
+func (p *ddlParser) acceptSymbol(symbol string) bool {
+	if !p.peek().isSymbol(symbol) {
+		return false
+	}
+
+	p.pos++
+
+	return true
+}
+
+func (p *ddlParser) expectKeyword(keywords ...string) error {
+	if !p.acceptKeyword(keywords...) {
+		return fmt.Errorf("expected [%s], got [%s]", strings.ToUpper(strings.Join(keywords, " ")), p.peek())
+	}
+
+	return nil
+}
+
+func (p *ddlParser) identifier() (string, error) {
+	token := p.next()
+	if token.Kind != _identifierToken {
+		return "", fmt.Errorf("expected an identifier, got [%s]", token)
+	}
+
+	return token.Text, nil
+}
+
+func (p *ddlParser) qualifiedName(defaultSchema string) (relationName, error) {
+	name, err := p.identifier()
+	if err != nil {
+		return relationName{}, err
+	}
+
+	if !p.acceptSymbol(".") {
+		return relationName{Schema: defaultSchema, Name: name}, nil
+	}
+
+	relation, err := p.identifier()
+	if err != nil {
+		return relationName{}, err
+	}
+
+	return relationName{Schema: name, Name: relation}, nil
+}
+
+// group returns the tokens between the parentheses starting at the current position
+func (p *ddlParser) group() ([]sqlToken, error) {
+	if !p.acceptSymbol("(") {
+		return nil, fmt.Errorf("expected [(], got [%s]", p.peek())
+	}
+
+	start := p.pos
+	for depth := 1; !p.done(); {
+		token := p.next()
+		switch {
+		case token.isSymbol("("):
+			depth++
+		case token.isSymbol(")"):
+			depth--
+			if depth == 0 {
+				return p.tokens[start : p.pos-1], nil
+			}
+		}
+	}
+
+	return nil, errors.New("unbalanced parentheses")
+}
+
+// skipUnit skips a single token or a parenthesized group of tokens
+func (p *ddlParser) skipUnit() error {
+	if p.peek().isSymbol("(") {
+		_, err := p.group()
+		return err
+	}
+
+	p.next()
+
+	return nil
+}
+
+// until returns the tokens up to one of the keywords outside of parentheses
+func (p *ddlParser) until(keywords ...string) []sqlToken {
+	start := p.pos
+	for !p.done() {
+		token := p.peek()
+		if token.Kind == _identifierToken && !token.Quoted && slices.Contains(keywords, token.Text) {
+			break
+		}
+
+		if err := p.skipUnit(); err != nil {
+			p.pos = len(p.tokens)
+		}
+	}
+
+	return p.tokens[start:p.pos]
+}
+
+func (p *ddlParser) identifierList() ([]string, error) {
+	tokens, err := p.group()
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, item := range splitList(tokens) {
+		if len(item) != 1 || item[0].Kind != _identifierToken {
+			return nil, fmt.Errorf("expected a column name, got [%s]", tokensText(item))
+		}
+
+		names = append(names, item[0].Text)
+	}
+
+	return names, nil
+}
+
+// ddlDataType is a parsed column data type with its length, precision and scale
+type ddlDataType struct {
+	Name      string
+	MaxLength int
+	Precision int
+	Scale     int
+	Serial    bool
+}
+
+// dataType parses a data type, named with the upper cased internal name
+// Postgres reports for it, such as INT4 for INTEGER, with the length of the
+// varchar(n) and char(n) types, the precision and scale of numeric types and
+// if it is a serial
+func (p *ddlParser) dataType() (ddlDataType, error) {
+	var words []string
+	var modifiers []sqlToken
+	array := false
+
+loop:
+	for !p.done() {
+		token := p.peek()
+		switch {
+		case token.isSymbol("("):
+			group, err := p.group()
+			if err != nil {
+				return ddlDataType{}, err
+			}
+
+			modifiers = group
+
+		case token.isSymbol("["):
+			for !p.done() && !p.next().isSymbol("]") {
+			}
+
+			array = true
+
+		case token.isSymbol("."):
+			p.next()
+			words = nil
+
+		case token.isKeyword("array"):
+			p.next()
+			array = true
+
+		case token.Kind == _identifierToken && (token.Quoted || !slices.Contains(_columnConstraintKeywords, token.Text)):
+			p.next()
+			words = append(words, token.Text)
+
+		default:
+			break loop
+		}
+	}
+
+	if len(words) == 0 {
+		return ddlDataType{}, fmt.Errorf("expected a data type, got [%s]", p.peek())
+	}
+
+	name := strings.Join(words, " ")
+	dataType := ddlDataType{
+		Name:   strings.ToUpper(words[0]),
+		Serial: slices.Contains(_serialTypes, name),
+	}
+
+	if sqlDataType, ok := _sqlTypeNames[name]; ok {
+		dataType.Name = sqlDataType
+	}
+
+	if array {
+		dataType.Name = "_" + dataType.Name
+		return dataType, nil
+	}
+
+	if dataType.Name == "VARCHAR" || dataType.Name == "BPCHAR" {
+		dataType.MaxLength = typeLength(modifiers, dataType.Name)
+	}
+
+	if dataType.Name == "NUMERIC" {
+		dataType.Precision, dataType.Scale = typePrecision(modifiers)
+	}
+
+	return dataType, nil
+}
+
+// typeLength returns the length of a character type, a char without one
+// has length 1 and a varchar has no limit
+func typeLength(modifiers []sqlToken, sqlDataType string) int {
+	if len(modifiers) == 1 && modifiers[0].Kind == _numberToken {
+		length, err := strconv.Atoi(modifiers[0].Text)
+		if err == nil {
+			return length
+		}
+	}
+
+	if len(modifiers) == 0 && sqlDataType == "BPCHAR" {
+		return 1
+	}
+
+	return 0
+}
+
+// typePrecision returns the precision and scale of a numeric type, which are
+// zero when not given
+func typePrecision(modifiers []sqlToken) (int, int) {
+	if len(modifiers) == 0 || modifiers[0].Kind != _numberToken {
+		return 0, 0
+	}
+
+	precision, err := strconv.Atoi(modifiers[0].Text)
+	if err != nil {
+		return 0, 0
+	}
+
+	if len(modifiers) == 3 && modifiers[1].isSymbol(",") && modifiers[2].Kind == _numberToken {
+		scale, err := strconv.Atoi(modifiers[2].Text)
+		if err == nil {
+			return precision, scale
+		}
+	}
+
+	return precision, 0
+}
+
+// skipExpression skips a column default expression, up to the next column constraint
+func (p *ddlParser) skipExpression() error {
+	if err := p.skipUnit(); err != nil {
+		return err
+	}
+
+	for !p.done() {
+		token := p.peek()
+		if token.Kind == _identifierToken && !token.Quoted && slices.Contains(_columnConstraintKeywords, token.Text) {
+			return nil
+		}
+
+		if err := p.skipUnit(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
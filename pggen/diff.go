@@ -0,0 +1,164 @@
+package pggen
+
+import (
+	"strconv"
+	"strings"
+)
+
+const _diffContextLines = 3
+
+type diffOp struct {
+	Kind byte // ' ', '-' or '+'
+	Line string
+}
+
+func splitLines(content string) []string {
+	if content == "" {
+		return nil
+	}
+
+	lines := strings.SplitAfter(content, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return lines
+}
+
+// diffLines computes the line edit script between from and to using the
+// longest common subsequence of both
+func diffLines(from, to []string) []diffOp {
+	lcs := make([][]int, len(from)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(to)+1)
+	}
+
+	for i := len(from) - 1; i >= 0; i-- {
+		for j := len(to) - 1; j >= 0; j-- {
+			if from[i] == to[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var ops []diffOp
+	i, j := 0, 0
+	for i < len(from) && j < len(to) {
+		switch {
+		case from[i] == to[j]:
+			ops = append(ops, diffOp{Kind: ' ', Line: from[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, diffOp{Kind: '-', Line: from[i]})
+			i++
+		default:
+			ops = append(ops, diffOp{Kind: '+', Line: to[j]})
+			j++
+		}
+	}
+
+	for ; i < len(from); i++ {
+		ops = append(ops, diffOp{Kind: '-', Line: from[i]})
+	}
+
+	for ; j < len(to); j++ {
+		ops = append(ops, diffOp{Kind: '+', Line: to[j]})
+	}
+
+	return ops
+}
+
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return strconv.Itoa(start) + ",0"
+	}
+
+	if count == 1 {
+		return strconv.Itoa(start + 1)
+	}
+
+	return strconv.Itoa(start+1) + "," + strconv.Itoa(count)
+}
+
+// UnifiedDiff renders the differences between from and to in the unified
+// format, returning an empty string when both are equal
+func UnifiedDiff(fromName, toName string, from, to []byte) string {
+	ops := diffLines(splitLines(string(from)), splitLines(string(to)))
+
+	var sb strings.Builder
+	for start := 0; start < len(ops); {
+		for start < len(ops) && ops[start].Kind == ' ' {
+			start++
+		}
+
+		if start == len(ops) {
+			break
+		}
+
+		hunkStart := max(start-_diffContextLines, 0)
+		hunkEnd := start
+		for k := start; k < len(ops); k++ {
+			if ops[k].Kind != ' ' {
+				hunkEnd = k + 1
+				continue
+			}
+
+			if k-hunkEnd >= 2*_diffContextLines {
+				break
+			}
+		}
+		hunkEnd = min(hunkEnd+_diffContextLines, len(ops))
+
+		fromStart, toStart := 0, 0
+		for _, op := range ops[:hunkStart] {
+			if op.Kind != '+' {
+				fromStart++
+			}
+
+			if op.Kind != '-' {
+				toStart++
+			}
+		}
+
+		fromCount, toCount := 0, 0
+		for _, op := range ops[hunkStart:hunkEnd] {
+			if op.Kind != '+' {
+				fromCount++
+			}
+
+			if op.Kind != '-' {
+				toCount++
+			}
+		}
+
+		if sb.Len() == 0 {
+			sb.WriteString("--- ")
+			sb.WriteString(fromName)
+			sb.WriteString("\n+++ ")
+			sb.WriteString(toName)
+			sb.WriteString("\n")
+		}
+
+		sb.WriteString("@@ -")
+		sb.WriteString(hunkRange(fromStart, fromCount))
+		sb.WriteString(" +")
+		sb.WriteString(hunkRange(toStart, toCount))
+		sb.WriteString(" @@\n")
+
+		for _, op := range ops[hunkStart:hunkEnd] {
+			sb.WriteByte(op.Kind)
+			sb.WriteString(op.Line)
+
+			if !strings.HasSuffix(op.Line, "\n") {
+				sb.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+
+		start = hunkEnd
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,297 @@
+package pggen
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+
+	_ "embed"
+
+	"github.com/iancoleman/strcase"
+)
+
+var (
+	//go:embed templates/docs/markdown.txt
+	_docsMarkdownTemplate string
+
+	//go:embed templates/docs/mermaid.txt
+	_docsMermaidTemplate string
+
+	//go:embed templates/docs/dot.txt
+	_docsDotTemplate string
+
+	_docsMarkdownGoTemplate = mustParseDocsTemplate("markdown", _docsMarkdownTemplate)
+	_docsMermaidGoTemplate  = mustParseDocsTemplate("mermaid", _docsMermaidTemplate)
+	_docsDotGoTemplate      = mustParseDocsTemplate("dot", _docsDotTemplate)
+)
+
+var _mermaidNameRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+// _docsFuncs are the helper functions of the docs templates, on top of the
+// ones of every template
+var _docsFuncs = template.FuncMap{
+	"sqlType":          (*PgColumn).sqlType,
+	"columnKeys":       columnKeys,
+	"constraintKind":   constraintKind,
+	"mdCell":           markdownCell,
+	"mdCode":           markdownCode,
+	"mdCodeList":       markdownCodeList,
+	"mermaidName":      mermaidName,
+	"mermaidAttribute": mermaidAttribute,
+	"mermaidRelation":  mermaidRelation,
+	"dotString":        strconv.Quote,
+	"dotEdge":          dotEdge,
+}
+
+// docsData is the data the docs templates are executed with, Tables and
+// Views hold the objects that are not ignored
+type docsData struct {
+	Schema    *PgSchema
+	Tables    []*PgTable
+	Views     []*PgTable
+	Relations []pgRelation
+	Diagram   string
+}
+
+func mustParseDocsTemplate(name, text string) *template.Template {
+	return template.Must(template.New(name).Funcs(_templateFuncs).Funcs(_docsFuncs).Parse(text))
+}
+
+// sqlType returns the type of the column as it is declared, such as
+// varchar(255), numeric(12,2) or text[]
+func (c *PgColumn) sqlType() string {
+	sqlType := sqlTypeDeclaration(c.SqlDataType)
+	if c.MaxLength > 0 {
+		sqlType += "(" + strconv.Itoa(c.MaxLength) + ")"
+	}
+
+	if c.Precision > 0 {
+		sqlType += "(" + strconv.Itoa(c.Precision) + "," + strconv.Itoa(c.Scale) + ")"
+	}
+
+	return sqlType
+}
+
+// isUnique tells if the columns are the primary key or an unique constraint
+// of the table
+func (t *PgTable) isUnique(columns []string) bool {
+	for _, constraint := range t.Constraints {
+		if (constraint.Type == _primaryKeyConstraint || constraint.Type == _uniqueConstraint) &&
+			slices.Equal(constraint.Columns, columns) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// columnKeys lists the keys a column is part of, foreign keys with the
+// column they reference
+func columnKeys(table *PgTable, column *PgColumn) string {
+	var keys []string
+	if column.IsPrimaryKey {
+		keys = append(keys, "PK")
+	}
+
+	for _, constraint := range table.Constraints {
+		i := slices.Index(constraint.Columns, column.Name)
+		if i < 0 {
+			continue
+		}
+
+		switch {
+		case constraint.Type == _foreignKeyConstraint && i < len(constraint.RefColumns):
+			keys = append(keys, "FK "+constraint.RefTable+"."+constraint.RefColumns[i])
+		case constraint.Type == _uniqueConstraint && len(constraint.Columns) == 1:
+			keys = append(keys, "UQ")
+		}
+	}
+
+	return strings.Join(keys, ", ")
+}
+
+func constraintKind(constraint *PgConstraint) string {
+	switch constraint.Type {
+	case _primaryKeyConstraint:
+		return "primary key"
+	case _uniqueConstraint:
+		return "unique"
+	case _foreignKeyConstraint:
+		return "foreign key"
+	case _checkConstraint:
+		return "check"
+	default:
+		return constraint.Type
+	}
+}
+
+// markdownCell escapes the text for a Markdown table cell
+func markdownCell(text string) string {
+	text = strings.ReplaceAll(text, "|", `\|`)
+	text = strings.ReplaceAll(text, "\r\n", "<br>")
+
+	return strings.ReplaceAll(text, "\n", "<br>")
+}
+
+func markdownCode(text string) string {
+	return "`" + markdownCell(text) + "`"
+}
+
+func markdownCodeList(values []string) string {
+	quoted := make([]string, len(values))
+	for i, value := range values {
+		quoted[i] = markdownCode(value)
+	}
+
+	return strings.Join(quoted, ", ")
+}
+
+func mermaidName(name string) string {
+	if _mermaidNameRegexp.MatchString(name) {
+		return name
+	}
+
+	return strconv.Quote(name)
+}
+
+// mermaidAttribute returns the attribute of an entity, Mermaid only allows
+// words in the type and name of attributes
+func mermaidAttribute(table *PgTable, column *PgColumn) string {
+	word := func(text string) string {
+		return strings.Map(func(r rune) rune {
+			if r == '_' || r == '-' || r == '(' || r == ')' || r == '[' || r == ']' ||
+				(r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+				return r
+			}
+
+			return '_'
+		}, text)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(word(column.sqlType()))
+	sb.WriteString(" ")
+	sb.WriteString(word(column.Name))
+
+	var keys []string
+	if column.IsPrimaryKey {
+		keys = append(keys, "PK")
+	}
+
+	for _, constraint := range table.Constraints {
+		if !slices.Contains(constraint.Columns, column.Name) {
+			continue
+		}
+
+		switch {
+		case constraint.Type == _foreignKeyConstraint && !slices.Contains(keys, "FK"):
+			keys = append(keys, "FK")
+		case constraint.Type == _uniqueConstraint && len(constraint.Columns) == 1:
+			keys = append(keys, "UK")
+		}
+	}
+
+	if len(keys) > 0 {
+		sb.WriteString(" ")
+		sb.WriteString(strings.Join(keys, ", "))
+	}
+
+	if !strIsEmpty(column.Comment) {
+		sb.WriteString(` "`)
+		sb.WriteString(strings.NewReplacer(`"`, "'", "\n", " ", "\r", "").Replace(column.Comment))
+		sb.WriteString(`"`)
+	}
+
+	return sb.String()
+}
+
+// mermaidRelation returns the relationship of a foreign key, the parent is
+// optional when a foreign key column is nullable and the children are at
+// most one when the foreign key columns are unique
+func mermaidRelation(relation pgRelation) string {
+	parent := "||"
+	for _, columnName := range relation.Constraint.Columns {
+		if relation.Child.column(columnName).Nullable {
+			parent = "|o"
+		}
+	}
+
+	children := "o{"
+	if relation.Child.isUnique(relation.Constraint.Columns) {
+		children = "o|"
+	}
+
+	return fmt.Sprintf(
+		"%s %s--%s %s : %s",
+		mermaidName(relation.Parent.Name),
+		parent,
+		children,
+		mermaidName(relation.Child.Name),
+		strconv.Quote(strings.Join(relation.Constraint.Columns, ", ")))
+}
+
+func dotEdge(relation pgRelation) string {
+	return fmt.Sprintf(
+		"%s:%s -> %s:%s",
+		strconv.Quote(relation.Child.Name),
+		strconv.Quote(relation.Constraint.Columns[0]),
+		strconv.Quote(relation.Parent.Name),
+		strconv.Quote(relation.Constraint.RefColumns[0]))
+}
+
+func (pcg *PgCodeGenerator) generateDocs(model *PgSchema, schema ConfigSchema) error {
+	log.Printf("Generating docs for tables and views")
+
+	linkRelations(model.Tables, model.Name, schema)
+
+	data := &docsData{
+		Schema: model,
+	}
+
+	for i := range model.Tables {
+		table := &model.Tables[i]
+		if schema.ShouldIgnore(table.Name) {
+			log.Printf("- Ignored docs generation for %s [%s]\n", table.Kind, table.Name)
+			continue
+		}
+
+		if table.Kind == _view {
+			data.Views = append(data.Views, table)
+			continue
+		}
+
+		data.Tables = append(data.Tables, table)
+		data.Relations = append(data.Relations, table.references...)
+	}
+
+	basePath := filepath.Join(schema.Docs.Dest, strcase.ToSnake(model.Name))
+	source := "schema [" + model.Name + "]"
+
+	diagram, err := executeTemplate(_docsMermaidGoTemplate, data)
+	if err != nil {
+		return fmt.Errorf("failed to generate the diagram of schema [%s], got error [%s]", model.Name, err.Error())
+	}
+
+	data.Diagram = diagram
+
+	markdown, err := executeTemplate(_docsMarkdownGoTemplate, data)
+	if err != nil {
+		return fmt.Errorf("failed to generate the data dictionary of schema [%s], got error [%s]", model.Name, err.Error())
+	}
+
+	dot, err := executeTemplate(_docsDotGoTemplate, data)
+	if err != nil {
+		return fmt.Errorf("failed to generate the DOT graph of schema [%s], got error [%s]", model.Name, err.Error())
+	}
+
+	pcg.addFile(basePath+".md", []byte(markdown), source)
+	pcg.addFile(basePath+".mmd", []byte("%% Code generated by pg_gen, DO NOT EDIT.\n"+diagram), source)
+	pcg.addFile(basePath+".dot", []byte(dot), source)
+
+	return nil
+}
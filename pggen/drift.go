@@ -0,0 +1,380 @@
+package pggen
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	_addedObject   = "added"
+	_removedObject = "removed"
+	_changedObject = "changed"
+)
+
+const (
+	_columnObject     = "column"
+	_constraintObject = "constraint"
+	_enumObject       = "enum"
+)
+
+// SchemaChange is a difference between two models, From and To hold the
+// definition of removed and added objects and both values of changed fields
+type SchemaChange struct {
+	Action string `json:"action"`
+	Kind   string `json:"kind"`
+	Name   string `json:"name"`
+	Field  string `json:"field,omitempty"`
+	From   string `json:"from,omitempty"`
+	To     string `json:"to,omitempty"`
+
+	schema         string
+	fromTable      *PgTable
+	toTable        *PgTable
+	fromColumn     *PgColumn
+	toColumn       *PgColumn
+	fromConstraint *PgConstraint
+	toConstraint   *PgConstraint
+	fromEnum       *PgEnum
+	toEnum         *PgEnum
+}
+
+func (c *PgConstraint) definition() string {
+	columns := "(" + strings.Join(c.Columns, ", ") + ")"
+
+	switch c.Type {
+	case _primaryKeyConstraint:
+		return "PRIMARY KEY " + columns
+	case _uniqueConstraint:
+		return "UNIQUE " + columns
+	case _foreignKeyConstraint:
+		return "FOREIGN KEY " + columns + " REFERENCES " + c.RefSchema + "." + c.RefTable + " (" + strings.Join(c.RefColumns, ", ") + ")"
+	default:
+		if strIsEmpty(c.Check) {
+			return "CHECK " + columns
+		}
+
+		return "CHECK (" + normalizedCheck(c.Check) + ")"
+	}
+}
+
+// normalizedCheck returns the check expression without its casts, its
+// enclosing parentheses and the parentheses around single values, with its
+// spacing normalized, so a migration and the database print the same
+// expression the same way
+func normalizedCheck(expression string) string {
+	tokens, err := tokenizeSQL(expression)
+	if err != nil {
+		return strings.TrimSpace(expression)
+	}
+
+	tokens = withoutCasts(tokens)
+
+	var result []sqlToken
+	for i := 0; i < len(tokens); i++ {
+		isValue := i+2 < len(tokens) && tokens[i].isSymbol("(") && tokens[i+1].Kind != _symbolToken && tokens[i+2].isSymbol(")")
+		isCall := i > 0 && tokens[i-1].Kind == _identifierToken
+		if isValue && !isCall {
+			result = append(result, tokens[i+1])
+			i += 2
+			continue
+		}
+
+		result = append(result, tokens[i])
+	}
+
+	return sqlText(withoutParens(result))
+}
+
+func tablesByName(tables []PgTable) map[string]*PgTable {
+	byName := make(map[string]*PgTable, len(tables))
+	for i := range tables {
+		byName[tables[i].Name] = &tables[i]
+	}
+
+	return byName
+}
+
+func enumsByName(enums []PgEnum) map[string]*PgEnum {
+	byName := make(map[string]*PgEnum, len(enums))
+	for i := range enums {
+		byName[enums[i].Name] = &enums[i]
+	}
+
+	return byName
+}
+
+// sortedNames returns the sorted union of the keys of both maps
+func sortedNames[T any](from, to map[string]T) []string {
+	names := slices.Collect(maps.Keys(from))
+	for name := range to {
+		if _, ok := from[name]; !ok {
+			names = append(names, name)
+		}
+	}
+
+	slices.Sort(names)
+
+	return names
+}
+
+// DiffSchemas returns the changes needed to turn the from model into the to
+// model, ordered by schema, object kind and name
+func DiffSchemas(from, to []PgSchema) []SchemaChange {
+	fromSchemas := make(map[string]PgSchema, len(from))
+	for _, schema := range from {
+		fromSchemas[schema.Name] = schema
+	}
+
+	toSchemas := make(map[string]PgSchema, len(to))
+	for _, schema := range to {
+		toSchemas[schema.Name] = schema
+	}
+
+	var changes []SchemaChange
+	for _, name := range sortedNames(fromSchemas, toSchemas) {
+		changes = append(changes, diffEnums(name, fromSchemas[name].Enums, toSchemas[name].Enums)...)
+		changes = append(changes, diffTables(name, fromSchemas[name].Tables, toSchemas[name].Tables)...)
+	}
+
+	return changes
+}
+
+func diffEnums(schemaName string, from, to []PgEnum) []SchemaChange {
+	fromEnums := enumsByName(from)
+	toEnums := enumsByName(to)
+
+	var changes []SchemaChange
+	for _, name := range sortedNames(fromEnums, toEnums) {
+		fromEnum, toEnum := fromEnums[name], toEnums[name]
+		qualifiedName := schemaName + "." + name
+
+		switch {
+		case toEnum == nil:
+			changes = append(changes, SchemaChange{
+				Action: _removedObject,
+				Kind:   _enumObject,
+				Name:   qualifiedName,
+				From:   strings.Join(fromEnum.Values, ", "),
+
+				schema:   schemaName,
+				fromEnum: fromEnum,
+			})
+
+		case fromEnum == nil:
+			changes = append(changes, SchemaChange{
+				Action: _addedObject,
+				Kind:   _enumObject,
+				Name:   qualifiedName,
+				To:     strings.Join(toEnum.Values, ", "),
+
+				schema: schemaName,
+				toEnum: toEnum,
+			})
+
+		case !slices.Equal(fromEnum.Values, toEnum.Values):
+			changes = append(changes, SchemaChange{
+				Action: _changedObject,
+				Kind:   _enumObject,
+				Name:   qualifiedName,
+				Field:  "values",
+				From:   strings.Join(fromEnum.Values, ", "),
+				To:     strings.Join(toEnum.Values, ", "),
+
+				schema:   schemaName,
+				fromEnum: fromEnum,
+				toEnum:   toEnum,
+			})
+		}
+	}
+
+	return changes
+}
+
+func diffTables(schemaName string, from, to []PgTable) []SchemaChange {
+	fromTables := tablesByName(from)
+	toTables := tablesByName(to)
+
+	var changes []SchemaChange
+	for _, name := range sortedNames(fromTables, toTables) {
+		fromTable, toTable := fromTables[name], toTables[name]
+		qualifiedName := schemaName + "." + name
+
+		if fromTable != nil && (toTable == nil || toTable.Kind != fromTable.Kind) {
+			changes = append(changes, SchemaChange{
+				Action: _removedObject,
+				Kind:   fromTable.Kind,
+				Name:   qualifiedName,
+
+				schema:    schemaName,
+				fromTable: fromTable,
+			})
+		}
+
+		if toTable != nil && (fromTable == nil || toTable.Kind != fromTable.Kind) {
+			changes = append(changes, SchemaChange{
+				Action: _addedObject,
+				Kind:   toTable.Kind,
+				Name:   qualifiedName,
+
+				schema:  schemaName,
+				toTable: toTable,
+			})
+		}
+
+		if fromTable != nil && toTable != nil && fromTable.Kind == toTable.Kind {
+			changes = append(changes, diffColumns(schemaName, fromTable, toTable)...)
+			changes = append(changes, diffConstraints(schemaName, fromTable, toTable)...)
+		}
+	}
+
+	return changes
+}
+
+func diffColumns(schemaName string, from, to *PgTable) []SchemaChange {
+	var changes []SchemaChange
+	tableName := schemaName + "." + from.Name
+
+	for _, fromColumn := range from.Columns {
+		qualifiedName := tableName + "." + fromColumn.Name
+
+		toColumn := to.column(fromColumn.Name)
+		if toColumn == nil {
+			changes = append(changes, SchemaChange{
+				Action: _removedObject,
+				Kind:   _columnObject,
+				Name:   qualifiedName,
+				From:   fromColumn.SqlDataType,
+
+				schema:     schemaName,
+				fromTable:  from,
+				toTable:    to,
+				fromColumn: &fromColumn,
+			})
+
+			continue
+		}
+
+		fieldChanged := func(field, fromValue, toValue string) {
+			if fromValue != toValue {
+				changes = append(changes, SchemaChange{
+					Action: _changedObject,
+					Kind:   _columnObject,
+					Name:   qualifiedName,
+					Field:  field,
+					From:   fromValue,
+					To:     toValue,
+
+					schema:     schemaName,
+					fromTable:  from,
+					toTable:    to,
+					fromColumn: &fromColumn,
+					toColumn:   toColumn,
+				})
+			}
+		}
+
+		fieldChanged("type", fromColumn.SqlDataType, toColumn.SqlDataType)
+		fieldChanged("nullable", strconv.FormatBool(fromColumn.Nullable), strconv.FormatBool(toColumn.Nullable))
+		fieldChanged("primary_key", strconv.FormatBool(fromColumn.IsPrimaryKey), strconv.FormatBool(toColumn.IsPrimaryKey))
+		fieldChanged("max_length", strconv.Itoa(fromColumn.MaxLength), strconv.Itoa(toColumn.MaxLength))
+		fieldChanged("precision", strconv.Itoa(fromColumn.Precision), strconv.Itoa(toColumn.Precision))
+		fieldChanged("scale", strconv.Itoa(fromColumn.Scale), strconv.Itoa(toColumn.Scale))
+		fieldChanged("default", fromColumn.Default, toColumn.Default)
+	}
+
+	for _, toColumn := range to.Columns {
+		if from.column(toColumn.Name) == nil {
+			changes = append(changes, SchemaChange{
+				Action: _addedObject,
+				Kind:   _columnObject,
+				Name:   tableName + "." + toColumn.Name,
+				To:     toColumn.SqlDataType,
+
+				schema:    schemaName,
+				fromTable: from,
+				toTable:   to,
+				toColumn:  &toColumn,
+			})
+		}
+	}
+
+	return changes
+}
+
+func diffConstraints(schemaName string, from, to *PgTable) []SchemaChange {
+	var changes []SchemaChange
+	tableName := schemaName + "." + from.Name
+
+	for _, fromConstraint := range from.Constraints {
+		qualifiedName := tableName + "." + fromConstraint.Name
+
+		toConstraint := to.constraint(fromConstraint.Name)
+		switch {
+		case toConstraint == nil:
+			changes = append(changes, SchemaChange{
+				Action: _removedObject,
+				Kind:   _constraintObject,
+				Name:   qualifiedName,
+				From:   fromConstraint.definition(),
+
+				schema:         schemaName,
+				fromTable:      from,
+				toTable:        to,
+				fromConstraint: &fromConstraint,
+			})
+
+		case fromConstraint.definition() != toConstraint.definition():
+			changes = append(changes, SchemaChange{
+				Action: _changedObject,
+				Kind:   _constraintObject,
+				Name:   qualifiedName,
+				Field:  "definition",
+				From:   fromConstraint.definition(),
+				To:     toConstraint.definition(),
+
+				schema:         schemaName,
+				fromTable:      from,
+				toTable:        to,
+				fromConstraint: &fromConstraint,
+				toConstraint:   toConstraint,
+			})
+		}
+	}
+
+	for _, toConstraint := range to.Constraints {
+		if from.constraint(toConstraint.Name) == nil {
+			changes = append(changes, SchemaChange{
+				Action: _addedObject,
+				Kind:   _constraintObject,
+				Name:   tableName + "." + toConstraint.Name,
+				To:     toConstraint.definition(),
+
+				schema:       schemaName,
+				fromTable:    from,
+				toTable:      to,
+				toConstraint: &toConstraint,
+			})
+		}
+	}
+
+	return changes
+}
+
+func PrintSchemaChanges(w io.Writer, changes []SchemaChange) {
+	for _, change := range changes {
+		switch {
+		case change.Action == _changedObject:
+			fmt.Fprintf(w, "%-8s %s [%s] %s [%s] -> [%s]\n", change.Action, change.Kind, change.Name, change.Field, change.From, change.To)
+		case change.From != "":
+			fmt.Fprintf(w, "%-8s %s [%s] (%s)\n", change.Action, change.Kind, change.Name, change.From)
+		case change.To != "":
+			fmt.Fprintf(w, "%-8s %s [%s] (%s)\n", change.Action, change.Kind, change.Name, change.To)
+		default:
+			fmt.Fprintf(w, "%-8s %s [%s]\n", change.Action, change.Kind, change.Name)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package pggen
+
+import "testing"
+
+func TestConstraintDefinition(t *testing.T) {
+	tests := []struct {
+		name       string
+		constraint PgConstraint
+		expected   string
+	}{
+		{
+			name:       "primary key",
+			constraint: PgConstraint{Type: _primaryKeyConstraint, Columns: []string{"id"}},
+			expected:   "PRIMARY KEY (id)",
+		},
+		{
+			name:       "foreign key",
+			constraint: PgConstraint{Type: _foreignKeyConstraint, Columns: []string{"owner_id"}, RefSchema: "public", RefTable: "owners", RefColumns: []string{"id"}},
+			expected:   "FOREIGN KEY (owner_id) REFERENCES public.owners (id)",
+		},
+		{
+			name:       "check written in a migration",
+			constraint: PgConstraint{Type: _checkConstraint, Columns: []string{"age"}, Check: "age >= 18"},
+			expected:   "CHECK (age >= 18)",
+		},
+		{
+			name:       "check printed by Postgres",
+			constraint: PgConstraint{Type: _checkConstraint, Columns: []string{"tier"}, Check: "((tier)::text  = 'free'::text)"},
+			expected:   "CHECK (tier = 'free')",
+		},
+		{
+			name:       "check calling a function",
+			constraint: PgConstraint{Type: _checkConstraint, Columns: []string{"name"}, Check: "(length(name) > 0)"},
+			expected:   "CHECK (length(name) > 0)",
+		},
+		{
+			name:       "check without expression",
+			constraint: PgConstraint{Type: _checkConstraint, Columns: []string{"age"}},
+			expected:   "CHECK (age)",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if definition := test.constraint.definition(); definition != test.expected {
+				t.Errorf("expected [%s], got [%s]", test.expected, definition)
+			}
+		})
+	}
+}
+
+func TestDiffSchemasCheckExpression(t *testing.T) {
+	schema := func(check string) []PgSchema {
+		return []PgSchema{{
+			Name: "public",
+			Tables: []PgTable{{
+				Kind:        _table,
+				Name:        "users",
+				Columns:     []PgColumn{{Name: "age", SqlDataType: "INT4"}},
+				Constraints: []PgConstraint{{Name: "users_age_check", Type: _checkConstraint, Columns: []string{"age"}, Check: check}},
+			}},
+		}}
+	}
+
+	if changes := DiffSchemas(schema("(age >= 18)"), schema("age >= 18")); len(changes) != 0 {
+		t.Errorf("expected no changes, got %+v", changes)
+	}
+
+	changes := DiffSchemas(schema("age >= 18"), schema("age >= 21"))
+	if len(changes) != 1 || changes[0].From != "CHECK (age >= 18)" || changes[0].To != "CHECK (age >= 21)" {
+		t.Errorf("expected the check expression to change, got %+v", changes)
+	}
+}
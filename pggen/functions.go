@@ -0,0 +1,753 @@
+package pggen
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"go/token"
+	"log"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+
+	_ "embed"
+
+	"github.com/iancoleman/strcase"
+)
+
+var (
+	//go:embed templates/go/functions.txt
+	_functionsTemplate string
+
+	//go:embed templates/go/function_many.txt
+	_functionManyTemplate string
+
+	//go:embed templates/go/function_one.txt
+	_functionOneTemplate string
+
+	//go:embed templates/go/function_exec.txt
+	_functionExecTemplate string
+
+	_functionsGoTemplate    = mustParseSnippet("functions", _functionsTemplate)
+	_functionManyGoTemplate = mustParseSnippet("function_many", _functionManyTemplate)
+	_functionOneGoTemplate  = mustParseSnippet("function_one", _functionOneTemplate)
+	_functionExecGoTemplate = mustParseSnippet("function_exec", _functionExecTemplate)
+)
+
+const (
+	_function  = "function"
+	_procedure = "procedure"
+)
+
+const (
+	_inArgMode       = "i"
+	_outArgMode      = "o"
+	_inOutArgMode    = "b"
+	_variadicArgMode = "v"
+	_tableArgMode    = "t"
+)
+
+// Go identifiers used by the generated function bodies, arguments named after
+// them are renamed to avoid shadowing
+var _reservedFunctionIdentifiers = []string{"ctx", "db", "query", "args", "params", "row", "rows", "err", "entity", "result"}
+
+// PgFunctionArg is an argument of a function or procedure
+type PgFunctionArg struct {
+	Name        string `json:"name,omitempty"`
+	Mode        string `json:"mode,omitempty"`
+	SqlDataType string `json:"sql_data_type,omitempty"`
+	GoDataType  string `json:"go_data_type,omitempty"`
+	HasDefault  bool   `json:"has_default,omitempty"`
+
+	goField string
+}
+
+func (a *PgFunctionArg) isInput() bool {
+	return a.Mode == _inArgMode || a.Mode == _inOutArgMode || a.Mode == _variadicArgMode
+}
+
+func (a *PgFunctionArg) isOutput() bool {
+	return a.Mode == _outArgMode || a.Mode == _inOutArgMode || a.Mode == _tableArgMode
+}
+
+// PgFunction is a function or procedure, as told by its Kind
+type PgFunction struct {
+	Name           string          `json:"name,omitempty"`
+	Kind           string          `json:"kind,omitempty"`
+	ReturnsSet     bool            `json:"returns_set,omitempty"`
+	ReturnType     string          `json:"return_type,omitempty"`
+	ReturnTypeKind string          `json:"return_type_kind,omitempty"`
+	ReturnTable    string          `json:"return_table,omitempty"`
+	Args           []PgFunctionArg `json:"args,omitempty"`
+	ResultColumns  []PgColumn      `json:"result_columns,omitempty"`
+	Comment        string          `json:"comment,omitempty"`
+
+	goName     string
+	goArgNames []string
+	rowEntity  string
+}
+
+// resultColumns returns the output columns of the function, which are
+// nullable unless annotated with @pg_gen:not_null, since Postgres does not
+// know if a function can return NULL
+func (f *PgFunction) resultColumns() []PgColumn {
+	var columns []PgColumn
+	for _, arg := range f.Args {
+		if arg.isOutput() {
+			columns = append(columns, PgColumn{
+				Name:        arg.Name,
+				SqlDataType: arg.SqlDataType,
+				GoDataType:  arg.GoDataType,
+				goField:     arg.goField,
+			})
+		}
+	}
+
+	if len(columns) == 0 && f.ReturnTypeKind == "c" {
+		columns = slices.Clone(f.ResultColumns)
+	}
+
+	for i := range columns {
+		columns[i].Nullable = !f.isNotNull(columns[i].Name)
+	}
+
+	return columns
+}
+
+// isNotNull reports if the output of the function is annotated as never null,
+// scalar results are named after the function, as they are by Postgres
+func (f *PgFunction) isNotNull(output string) bool {
+	_, annotations := parseAnnotations(f.Comment)
+
+	return notNullAnnotation(annotations, output)
+}
+
+// validateAnnotations fails on annotations other than @pg_gen:not_null in the
+// comment of the function
+func (f *PgFunction) validateAnnotations() error {
+	_, annotations := parseAnnotations(f.Comment)
+	for _, name := range slices.Sorted(maps.Keys(annotations)) {
+		if name != _notNullAnnotation {
+			return fmt.Errorf("unknown annotation [@pg_gen:%s] in the comment of %s [%s]", name, f.Kind, f.Name)
+		}
+	}
+
+	return nil
+}
+
+func (f *PgFunction) isVoid() bool {
+	return strings.ToUpper(f.ReturnType) == "VOID" && len(f.resultColumns()) == 0
+}
+
+// isSupported reports if the function result can be mapped to Go, records
+// without OUT parameters and pseudo types such as triggers cannot
+func (f *PgFunction) isSupported() bool {
+	if f.isVoid() || len(f.resultColumns()) > 0 {
+		return true
+	}
+
+	return f.ReturnTypeKind != "p"
+}
+
+func (f *PgFunction) rowType() string {
+	if len(f.resultColumns()) == 0 {
+		return f.scalarGoDataType()
+	}
+
+	if f.rowEntity != "" {
+		return f.rowEntity
+	}
+
+	return f.goName + "Row"
+}
+
+func (f *PgFunction) scalarGoDataType() string {
+	goType := goDataType(f.ReturnType)
+	if goType == "any" || f.isNotNull(f.Name) {
+		return goType
+	}
+
+	return "*" + goType
+}
+
+func (f *PgFunction) rowTable() *PgTable {
+	return &PgTable{
+		Kind:    _function,
+		Name:    f.Name,
+		Columns: f.resultColumns(),
+	}
+}
+
+// hasOptionalArgs reports if the defaulted arguments can be omitted on calls,
+// which requires them to be named so they can be passed with named notation
+func (f *PgFunction) hasOptionalArgs() bool {
+	if f.Kind == _procedure {
+		return false
+	}
+
+	hasDefaults := false
+	for _, arg := range f.Args {
+		if arg.HasDefault {
+			if strIsEmpty(arg.Name) {
+				return false
+			}
+
+			hasDefaults = true
+		}
+	}
+
+	return hasDefaults
+}
+
+func (f *PgFunction) goFunctionParams() string {
+	var sb strings.Builder
+
+	optional := f.hasOptionalArgs()
+	for i, arg := range f.Args {
+		if !arg.isInput() {
+			continue
+		}
+
+		sb.WriteString(", ")
+		sb.WriteString(f.goArgNames[i])
+		sb.WriteString(" ")
+
+		if arg.Mode == _variadicArgMode {
+			sb.WriteString("...")
+		} else if optional && arg.HasDefault && arg.GoDataType != "any" {
+			sb.WriteString("*")
+		}
+
+		sb.WriteString(arg.GoDataType)
+	}
+
+	return sb.String()
+}
+
+func (f *PgFunction) sqlName(schemaName string) string {
+	return "\"" + schemaName + "\".\"" + f.Name + "\""
+}
+
+func (f *PgFunction) sqlCallPrefix(schemaName string) string {
+	if f.Kind == _procedure {
+		return "CALL " + f.sqlName(schemaName)
+	}
+
+	columns := f.resultColumns()
+	if len(columns) == 0 {
+		if f.isVoid() {
+			return "SELECT " + f.sqlName(schemaName)
+		}
+
+		return "SELECT * FROM " + f.sqlName(schemaName)
+	}
+
+	return "SELECT " + (&PgTable{Columns: columns}).sqlSelectFields() + " FROM " + f.sqlName(schemaName)
+}
+
+func (f *PgFunction) sqlPlaceholder(arg PgFunctionArg, position string) string {
+	var sb strings.Builder
+
+	if arg.Mode == _variadicArgMode {
+		sb.WriteString("VARIADIC ")
+	}
+
+	sb.WriteString("$")
+	sb.WriteString(position)
+	sb.WriteString("::")
+	sb.WriteString(arg.SqlDataType)
+
+	return sb.String()
+}
+
+func (f *PgFunction) goFunctionQuery(schemaName string) string {
+	if f.hasOptionalArgs() {
+		return f.goDynamicFunctionQuery(schemaName)
+	}
+
+	var placeholders []string
+	var values []string
+	for i, arg := range f.Args {
+		if f.Kind == _procedure && arg.Mode == _outArgMode {
+			placeholders = append(placeholders, "NULL")
+			continue
+		}
+
+		if !arg.isInput() {
+			continue
+		}
+
+		values = append(values, f.goArgNames[i])
+		placeholders = append(placeholders, f.sqlPlaceholder(arg, strconv.Itoa(len(values))))
+	}
+
+	var sb strings.Builder
+	sb.WriteString("const query = `")
+	sb.WriteString(f.sqlCallPrefix(schemaName))
+	sb.WriteString("(")
+	sb.WriteString(strings.Join(placeholders, ", "))
+	sb.WriteString(")`\n\nargs := []any{")
+	sb.WriteString(strings.Join(values, ", "))
+	sb.WriteString("}")
+
+	return sb.String()
+}
+
+func (f *PgFunction) goDynamicFunctionQuery(schemaName string) string {
+	var placeholders []string
+	var values []string
+	var optionals strings.Builder
+	for i, arg := range f.Args {
+		if !arg.isInput() {
+			continue
+		}
+
+		if !arg.HasDefault {
+			values = append(values, f.goArgNames[i])
+			placeholders = append(placeholders, "`"+f.sqlPlaceholder(arg, strconv.Itoa(len(values)))+"`")
+			continue
+		}
+
+		optionals.WriteString("\n\nif ")
+		optionals.WriteString(f.goArgNames[i])
+		optionals.WriteString(" != nil {\nargs = append(args, ")
+		optionals.WriteString(f.goArgNames[i])
+		optionals.WriteString(")\nparams = append(params, `\"")
+		optionals.WriteString(arg.Name)
+		optionals.WriteString("\" => $`+strconv.Itoa(len(args))+`::")
+		optionals.WriteString(arg.SqlDataType)
+		optionals.WriteString("`)\n}")
+	}
+
+	var sb strings.Builder
+	sb.WriteString("args := []any{")
+	sb.WriteString(strings.Join(values, ", "))
+	sb.WriteString("}\nparams := []string{")
+	sb.WriteString(strings.Join(placeholders, ", "))
+	sb.WriteString("}")
+	sb.WriteString(optionals.String())
+	sb.WriteString("\n\nquery := `")
+	sb.WriteString(f.sqlCallPrefix(schemaName))
+	sb.WriteString("(` + strings.Join(params, \", \") + `)`")
+
+	return sb.String()
+}
+
+func (f *PgFunction) goFunctionScanFields() string {
+	if len(f.resultColumns()) == 0 {
+		return "&entity"
+	}
+
+	return f.rowTable().goSelectManyScanFields()
+}
+
+func (f *PgFunction) values(schemaName string) map[string]string {
+	resultType := f.rowType()
+	zeroValue := "entity"
+	result := "entity"
+	if len(f.resultColumns()) > 0 {
+		resultType = "*" + resultType
+		zeroValue = "nil"
+		result = "&entity"
+	}
+
+	return map[string]string{
+		"goFunctionName":       f.goName,
+		"goFunctionParams":     f.goFunctionParams(),
+		"goFunctionQuery":      f.goFunctionQuery(schemaName),
+		"goFunctionRowType":    f.rowType(),
+		"goFunctionResultType": resultType,
+		"goFunctionZeroValue":  zeroValue,
+		"goFunctionResult":     result,
+		"goFunctionScanFields": f.goFunctionScanFields(),
+	}
+}
+
+func (f *PgFunction) template() *template.Template {
+	if f.isVoid() {
+		return _functionExecGoTemplate
+	}
+
+	if f.ReturnsSet {
+		return _functionManyGoTemplate
+	}
+
+	return _functionOneGoTemplate
+}
+
+// resolveGoNames names the wrapper of the function following the naming
+// config, overloads are suffixed with a counter (ex: Touch and Touch2)
+func (f *PgFunction) resolveGoNames(overloads map[string]int, tables []PgTable, naming *ConfigNaming) {
+	overloads[f.Name]++
+	f.goName = naming.goIdentifier(f.Name)
+	if overloads[f.Name] > 1 {
+		f.goName += strconv.Itoa(overloads[f.Name])
+	}
+
+	f.goArgNames = make([]string, len(f.Args))
+	usedArgNames := make(map[string]bool, len(f.Args))
+	for i, arg := range f.Args {
+		argName := strcase.ToLowerCamel(arg.Name)
+		if strIsEmpty(argName) {
+			argName = "arg" + strconv.Itoa(i+1)
+		}
+
+		if token.IsKeyword(argName) || isReservedFunctionIdentifier(argName) || usedArgNames[argName] {
+			argName += "Arg" + strconv.Itoa(i+1)
+		}
+
+		usedArgNames[argName] = true
+		f.goArgNames[i] = argName
+		f.Args[i].goField = naming.goIdentifier(arg.Name)
+	}
+
+	f.rowEntity = ""
+	entity := f.returnEntity(tables)
+	if entity != nil {
+		f.rowEntity = entity.entityName()
+	}
+
+	naming.nameColumns(f.ResultColumns, entity)
+}
+
+// declareGoNames declares the wrapper and the row type of the function in the
+// package
+func (f *PgFunction) declareGoNames(names *goNames) error {
+	source := f.Kind + " [" + f.Name + "]"
+	if err := names.declare(f.goName, source); err != nil {
+		return err
+	}
+
+	if len(f.resultColumns()) == 0 || f.rowEntity != "" {
+		return nil
+	}
+
+	if err := names.declare(f.rowType(), "row type of "+source); err != nil {
+		return err
+	}
+
+	return declareRowFields(names.schema, f.rowType(), f.resultColumns())
+}
+
+// returnEntity returns the table whose entity the rows of the function are
+// scanned into, nil when it has a row type of its own
+func (f *PgFunction) returnEntity(tables []PgTable) *PgTable {
+	if f.ReturnTypeKind != "c" || f.ReturnTable == "" {
+		return nil
+	}
+
+	for _, arg := range f.Args {
+		if arg.isOutput() {
+			return nil
+		}
+	}
+
+	for i := range tables {
+		if tables[i].Kind == _table && tables[i].Name == f.ReturnTable {
+			return &tables[i]
+		}
+	}
+
+	return nil
+}
+
+func isReservedFunctionIdentifier(name string) bool {
+	for _, identifier := range _reservedFunctionIdentifiers {
+		if identifier == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (pcg *PgCodeGenerator) getPgFunctions(ctx context.Context, schema string) ([]PgFunction, error) {
+	const query = `
+	SELECT
+		p.proname AS name,
+		(CASE WHEN p.prokind = 'p' THEN 'procedure' ELSE 'function' END) AS kind,
+		p.proretset AS returns_set,
+		UPPER(rt.typname) AS return_type,
+		rt.typtype::text AS return_type_kind,
+		COALESCE(rc.relname, '') AS return_table,
+		p.pronargdefaults AS defaults_count,
+		COALESCE((
+			SELECT
+				json_agg(json_build_object(
+					'name', COALESCE(p.proargnames[a.ord], ''),
+					'mode', COALESCE(p.proargmodes[a.ord]::text, 'i'),
+					'sql_data_type', UPPER(t.typname)
+				) ORDER BY a.ord)
+			FROM
+				unnest(COALESCE(p.proallargtypes, p.proargtypes::oid[])) WITH ORDINALITY AS a(type_oid, ord)
+			INNER JOIN pg_type t ON
+				t.oid = a.type_oid
+		), '[]') AS args,
+		COALESCE((
+			SELECT
+				json_agg(json_build_object(
+					'name', ra.attname,
+					'sql_data_type', UPPER(rat.typname),
+					'is_primary_key', false
+				) ORDER BY ra.attnum)
+			FROM
+				pg_attribute ra
+			INNER JOIN pg_type rat ON
+				rat.oid = ra.atttypid
+			WHERE
+				ra.attrelid = rt.typrelid
+				AND ra.attnum > 0
+				AND NOT ra.attisdropped
+		), '[]') AS result_columns,
+		COALESCE(obj_description(p.oid, 'pg_proc'), '') AS comment
+	FROM
+		pg_proc p
+	INNER JOIN pg_namespace n ON
+		n.oid = p.pronamespace
+	INNER JOIN pg_type rt ON
+		rt.oid = p.prorettype
+	LEFT JOIN pg_class rc ON
+		rc.oid = rt.typrelid
+	WHERE
+		n.nspname = $1
+		AND p.prokind IN ('f', 'p')
+		AND NOT EXISTS (
+			SELECT
+				1
+			FROM
+				pg_depend d
+			WHERE
+				d.classid = 'pg_proc'::regclass
+				AND d.objid = p.oid
+				AND d.deptype = 'e'
+		)
+	ORDER BY
+		p.proname,
+		p.oid
+	`
+
+	rows, err := pcg.db.QueryContext(ctx, query, schema)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var functions []PgFunction
+	for rows.Next() {
+		var function PgFunction
+		var defaultsCount int
+		var argsJson []byte
+		var resultColumnsJson []byte
+
+		err := rows.Scan(
+			&function.Name,
+			&function.Kind,
+			&function.ReturnsSet,
+			&function.ReturnType,
+			&function.ReturnTypeKind,
+			&function.ReturnTable,
+			&defaultsCount,
+			&argsJson,
+			&resultColumnsJson,
+			&function.Comment)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := json.Unmarshal(argsJson, &function.Args); err != nil {
+			return nil, err
+		}
+
+		if err := json.Unmarshal(resultColumnsJson, &function.ResultColumns); err != nil {
+			return nil, err
+		}
+
+		for i := range function.Args {
+			function.Args[i].GoDataType = goDataType(function.Args[i].SqlDataType)
+		}
+
+		for i := len(function.Args) - 1; i >= 0 && defaultsCount > 0; i-- {
+			if function.Args[i].isInput() {
+				function.Args[i].HasDefault = true
+				defaultsCount--
+			}
+		}
+
+		for i := range function.ResultColumns {
+			function.ResultColumns[i].GoDataType = goDataType(function.ResultColumns[i].SqlDataType)
+		}
+
+		functions = append(functions, function)
+	}
+
+	return functions, nil
+}
+
+func (pcg *PgCodeGenerator) functionsFilepath(rootDirectory, packageName string) string {
+	var sb strings.Builder
+	sb.WriteString(rootDirectory)
+	sb.WriteString("/")
+	sb.WriteString(packageName)
+	sb.WriteString("_functions.go")
+
+	return filepath.Clean(sb.String())
+}
+
+func goFunctionsImports(functions []PgFunction) string {
+	var goTypes strings.Builder
+	packages := []string{"context", "database/sql"}
+	hasOptionalArgs := false
+	for _, function := range functions {
+		goTypes.WriteString(function.goFunctionParams())
+		goTypes.WriteString(function.rowType())
+
+		for _, column := range function.resultColumns() {
+			goTypes.WriteString(column.GoDataType)
+		}
+
+		if function.hasOptionalArgs() {
+			hasOptionalArgs = true
+		}
+	}
+
+	if hasOptionalArgs {
+		packages = append(packages, "strconv", "strings")
+	}
+
+	return goImports(goTypes.String(), packages...)
+}
+
+// goImports lists the given standard library packages followed by the
+// packages required by the Go data types used in goTypes
+func goImports(goTypes string, packages ...string) string {
+	var imports []string
+	for _, pkg := range packages {
+		imports = append(imports, strconv.Quote(pkg))
+	}
+
+	if strings.Contains(goTypes, "time.") {
+		imports = append(imports, "\"time\"")
+	}
+
+	if strings.Contains(goTypes, "uuid.") {
+		imports = append(imports, "\n\"github.com/google/uuid\"")
+	}
+
+	return strings.Join(imports, "\n")
+}
+
+// goTableImports lists the given standard library packages followed by the
+// packages required by the columns of the table
+func goTableImports(table *PgTable, packages ...string) string {
+	var goTypes strings.Builder
+	for _, column := range table.Columns {
+		goTypes.WriteString(column.GoDataType)
+		goTypes.WriteString("\n")
+	}
+
+	imports := goImports(goTypes.String(), packages...)
+	for _, column := range table.Columns {
+		if strIsEmpty(column.goImport) || strings.Contains(imports, strconv.Quote(column.goImport)) {
+			continue
+		}
+
+		if !strings.Contains(imports, "\n\n") {
+			imports += "\n"
+		}
+
+		imports += "\n" + strconv.Quote(column.goImport)
+	}
+
+	return imports
+}
+
+func (pcg *PgCodeGenerator) generateCodeForFunctions(
+	functions []PgFunction,
+	tables []PgTable,
+	schemaName string,
+	schema ConfigSchema,
+	names *goNames,
+	rootDirectory string,
+	packageName string,
+	tags []ConfigTag,
+) error {
+	log.Printf("Generating code for functions and procedures")
+
+	overloads := make(map[string]int, len(functions))
+	var generated []PgFunction
+	for _, function := range functions {
+		if schema.ShouldIgnore(function.Name) {
+			log.Printf("- Ignored code generation for %s [%s]\n", function.Kind, function.Name)
+			continue
+		}
+
+		if !function.isSupported() {
+			log.Printf("- Skipped code generation for %s [%s], its return type [%s] is not supported\n", function.Kind, function.Name, function.ReturnType)
+			continue
+		}
+
+		if err := function.validateAnnotations(); err != nil {
+			return err
+		}
+
+		function.resolveGoNames(overloads, tables, &schema.Naming)
+		if err := function.declareGoNames(names); err != nil {
+			return err
+		}
+
+		generated = append(generated, function)
+	}
+
+	if len(generated) == 0 {
+		return nil
+	}
+
+	var rowTypes strings.Builder
+	var code strings.Builder
+	for _, function := range generated {
+		if len(function.resultColumns()) > 0 && function.rowEntity == "" {
+			rowTypes.WriteString("type ")
+			rowTypes.WriteString(function.rowType())
+			rowTypes.WriteString(" struct {\n")
+			row := function.rowTable()
+			row.applyGoTags(tags)
+			rowTypes.WriteString(row.goEntityFields())
+			rowTypes.WriteString("}\n\n")
+		}
+
+		functionCode, err := executeTemplate(function.template(), function.values(schemaName))
+		if err != nil {
+			return fmt.Errorf("failed to generate code for %s [%s], got error [%s]", function.Kind, function.Name, err.Error())
+		}
+
+		code.WriteString(functionCode)
+	}
+
+	rawCode, err := executeTemplate(_functionsGoTemplate, map[string]string{
+		"goPackageName":      packageName,
+		"goImports":          goFunctionsImports(generated),
+		"goFunctionRowTypes": rowTypes.String(),
+		"goFunctions":        code.String(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to generate code for functions of package [%s], got error [%s]", packageName, err.Error())
+	}
+
+	formattedCode, err := format.Source([]byte(rawCode))
+	if err != nil {
+		return fmt.Errorf("failed to generate code for functions of package [%s], got error [%s]", packageName, err.Error())
+	}
+
+	var sources []string
+	for _, function := range generated {
+		sources = append(sources, function.Kind+" ["+function.Name+"]")
+	}
+
+	pcg.addFile(pcg.functionsFilepath(rootDirectory, packageName), formattedCode, strings.Join(sources, ", "))
+
+	return nil
+}
@@ -0,0 +1,243 @@
+package pggen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+	"github.com/iancoleman/strcase"
+)
+
+const (
+	_jsonSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+	_jsonSchemaComment = "Code generated by pg_gen, DO NOT EDIT."
+)
+
+// jsonSchema is the subset of JSON Schema 2020-12 used to describe the
+// entities, Type is a string or a list of strings when the value is nullable
+type jsonSchema struct {
+	Comment              string               `json:"$comment,omitempty"`
+	Schema               string               `json:"$schema,omitempty"`
+	Title                string               `json:"title,omitempty"`
+	Description          string               `json:"description,omitempty"`
+	Type                 any                  `json:"type,omitempty"`
+	Format               string               `json:"format,omitempty"`
+	ContentEncoding      string               `json:"contentEncoding,omitempty"`
+	MaxLength            int                  `json:"maxLength,omitempty"`
+	Enum                 []any                `json:"enum,omitempty"`
+	Items                *jsonSchema          `json:"items,omitempty"`
+	Properties           jsonSchemaProperties `json:"properties,omitempty"`
+	Required             []string             `json:"required,omitempty"`
+	AdditionalProperties *bool                `json:"additionalProperties,omitempty"`
+}
+
+type jsonSchemaProperty struct {
+	Name   string
+	Schema *jsonSchema
+}
+
+// jsonSchemaProperties is encoded as an object keeping the order of the
+// columns
+type jsonSchemaProperties []jsonSchemaProperty
+
+func (jsp jsonSchemaProperties) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString("{")
+
+	for i, property := range jsp {
+		if i > 0 {
+			buf.WriteString(",")
+		}
+
+		name, err := json.Marshal(property.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		schema, err := json.Marshal(property.Schema)
+		if err != nil {
+			return nil, err
+		}
+
+		buf.Write(name)
+		buf.WriteString(":")
+		buf.Write(schema)
+	}
+
+	buf.WriteString("}")
+
+	return buf.Bytes(), nil
+}
+
+// jsonSchemaType maps a column type to the JSON the generated entities are
+// encoded to, dates are time.Time values and so are encoded as date-times
+func jsonSchemaType(schema *PgSchema, sqlDataType string) *jsonSchema {
+	sqlDataType = strings.ToLower(sqlDataType)
+	if element, ok := strings.CutPrefix(sqlDataType, "_"); ok {
+		return &jsonSchema{
+			Type:  "array",
+			Items: jsonSchemaType(schema, element),
+		}
+	}
+
+	if enum := schema.enum(sqlDataType); enum != nil {
+		values := make([]any, len(enum.Values))
+		for i, value := range enum.Values {
+			values[i] = value
+		}
+
+		return &jsonSchema{Type: "string", Enum: values}
+	}
+
+	switch sqlDataType {
+	case "int2", "int4", "smallint", "integer":
+		return &jsonSchema{Type: "integer", Format: "int32"}
+	case "int8", "bigint", "oid":
+		return &jsonSchema{Type: "integer", Format: "int64"}
+	case "float4", "real":
+		return &jsonSchema{Type: "number", Format: "float"}
+	case "float8", "double precision":
+		return &jsonSchema{Type: "number", Format: "double"}
+	case "bool", "boolean":
+		return &jsonSchema{Type: "boolean"}
+	case "uuid":
+		return &jsonSchema{Type: "string", Format: "uuid"}
+	case "timestamp", "timestamptz", "date":
+		return &jsonSchema{Type: "string", Format: "date-time"}
+	case "bytea":
+		return &jsonSchema{Type: "string", ContentEncoding: "base64"}
+	case "text", "varchar", "bpchar", "char", "citext", "name", "numeric", "decimal", "money",
+		"time", "timetz", "interval", "inet", "cidr", "macaddr":
+		return &jsonSchema{Type: "string"}
+	default:
+		return &jsonSchema{}
+	}
+}
+
+// jsonSchemaColumn returns the schema of a column, allowing null when it is
+// nullable
+func jsonSchemaColumn(schema *PgSchema, column *PgColumn) *jsonSchema {
+	columnSchema := jsonSchemaType(schema, column.SqlDataType)
+	if columnSchema.Type == "string" && column.MaxLength > 0 {
+		columnSchema.MaxLength = column.MaxLength
+	}
+
+	if column.Nullable && columnSchema.Type != nil {
+		columnSchema.Type = []string{columnSchema.Type.(string), "null"}
+		if columnSchema.Enum != nil {
+			columnSchema.Enum = append(columnSchema.Enum, nil)
+		}
+	}
+
+	return columnSchema
+}
+
+// jsonSchemaTable returns the schema of the entity of a table. Entities
+// always encode every field, so every property is required
+func jsonSchemaTable(schema *PgSchema, table *PgTable) *jsonSchema {
+	additionalProperties := false
+	tableSchema := &jsonSchema{
+		Title:                table.entityName(),
+		Description:          table.Comment,
+		Type:                 "object",
+		Properties:           make(jsonSchemaProperties, 0, len(table.Columns)),
+		Required:             make([]string, 0, len(table.Columns)),
+		AdditionalProperties: &additionalProperties,
+	}
+
+	for i := range table.Columns {
+		column := &table.Columns[i]
+		name := strcase.ToSnake(column.Name)
+		columnSchema := jsonSchemaColumn(schema, column)
+		columnSchema.Description = column.Comment
+
+		tableSchema.Properties = append(tableSchema.Properties, jsonSchemaProperty{
+			Name:   name,
+			Schema: columnSchema,
+		})
+		tableSchema.Required = append(tableSchema.Required, name)
+	}
+
+	return tableSchema
+}
+
+func encodeJsonSchema(value any) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+
+	if err := encoder.Encode(value); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// openAPIComponents encodes the schemas as the components of an OpenAPI 3.1
+// document, to be referenced or merged by the API specs
+func openAPIComponents(schemas jsonSchemaProperties) ([]byte, error) {
+	content, err := encodeJsonSchema(map[string]any{
+		"components": map[string]any{
+			"schemas": schemas,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	content, err = yaml.JSONToYAML(content)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte("# "+_jsonSchemaComment+"\n"), content...), nil
+}
+
+func (pcg *PgCodeGenerator) generateCodeForJsonSchema(model *PgSchema, schema ConfigSchema) error {
+	log.Printf("Generating JSON Schema for tables and views")
+
+	rootDirectory := schema.JsonSchema.Dest
+
+	var components jsonSchemaProperties
+	for i := range model.Tables {
+		table := &model.Tables[i]
+		if schema.ShouldIgnore(table.Name) {
+			log.Printf("- Ignored JSON Schema generation for %s [%s]\n", table.Kind, table.Name)
+			continue
+		}
+
+		tableSchema := jsonSchemaTable(model, table)
+		components = append(components, jsonSchemaProperty{
+			Name:   tableSchema.Title,
+			Schema: tableSchema,
+		})
+
+		document := *tableSchema
+		document.Comment = _jsonSchemaComment
+		document.Schema = _jsonSchemaDialect
+
+		content, err := encodeJsonSchema(&document)
+		if err != nil {
+			return fmt.Errorf("failed to generate JSON Schema for %s [%s], got error [%s]", table.Kind, table.Name, err.Error())
+		}
+
+		pcg.addFile(
+			filepath.Join(rootDirectory, strcase.ToSnake(table.Name)+".schema.json"),
+			content,
+			table.Kind+" ["+table.Name+"]")
+	}
+
+	content, err := openAPIComponents(components)
+	if err != nil {
+		return fmt.Errorf("failed to generate the OpenAPI components of schema [%s], got error [%s]", model.Name, err.Error())
+	}
+
+	pcg.addFile(filepath.Join(rootDirectory, "openapi.yaml"), content, "schema ["+model.Name+"]")
+
+	return nil
+}
@@ -0,0 +1,419 @@
+package pggen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// LiveDatabaseSource is the migrate-diff source that introspects the database
+const LiveDatabaseSource = "db"
+
+// LoadModel loads the model from a source, which is the live database, a
+// schema file when it ends with .json, or a migrations directory or file
+func LoadModel(ctx context.Context, config *Config, source string) ([]PgSchema, error) {
+	modelConfig := *config
+	modelConfig.SchemaFile = ""
+	modelConfig.Migrations = ""
+
+	switch {
+	case source == LiveDatabaseSource:
+		if strIsEmpty(config.DSN) {
+			return nil, errors.New("$.dsn must be present and not be blank to introspect the database")
+		}
+
+	case filepath.Ext(source) == ".json":
+		modelConfig.SchemaFile = source
+
+	default:
+		modelConfig.Migrations = source
+	}
+
+	pgGen, err := NewPgCodeGenerator(&modelConfig)
+	if err != nil {
+		return nil, err
+	}
+	defer pgGen.Close()
+
+	return pgGen.Introspect(ctx)
+}
+
+func quoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+func quoteLiteral(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+func qualifiedIdentifier(schema, name string) string {
+	return quoteIdentifier(schema) + "." + quoteIdentifier(name)
+}
+
+func quoteIdentifiers(names []string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = quoteIdentifier(name)
+	}
+
+	return strings.Join(quoted, ", ")
+}
+
+// sqlTypeDeclaration returns the type of a column as it is declared, array
+// types are reported by Postgres with an underscore prefix
+func sqlTypeDeclaration(sqlDataType string) string {
+	sqlDataType = strings.ToLower(sqlDataType)
+	if element, ok := strings.CutPrefix(sqlDataType, "_"); ok {
+		return element + "[]"
+	}
+
+	return sqlDataType
+}
+
+// _serialTypeNames are the serial types of the integer types
+var _serialTypeNames = map[string]string{"INT2": "smallserial", "INT4": "serial", "INT8": "bigserial"}
+
+// sqlSerialType returns the serial type of a column whose default is the
+// nextval of the sequence Postgres creates for serial columns, false when
+// the column is not a serial
+func sqlSerialType(tableName string, column *PgColumn) (string, bool) {
+	if column.Default != "nextval('"+tableName+"_"+column.Name+"_seq'::regclass)" {
+		return "", false
+	}
+
+	serialType, ok := _serialTypeNames[strings.ToUpper(column.SqlDataType)]
+	return serialType, ok
+}
+
+// sqlColumnDefinition returns the column as it is declared in CREATE TABLE
+// and ADD COLUMN, with its length, precision, scale and default
+func sqlColumnDefinition(tableName string, column *PgColumn) string {
+	definition := quoteIdentifier(column.Name) + " "
+	if serialType, ok := sqlSerialType(tableName, column); ok {
+		definition += serialType
+	} else {
+		definition += column.sqlType()
+		if !strIsEmpty(column.Default) {
+			definition += " DEFAULT " + column.Default
+		}
+	}
+
+	if !column.Nullable {
+		definition += " NOT NULL"
+	}
+
+	return definition
+}
+
+func sqlConstraintDefinition(constraint *PgConstraint) string {
+	var sb strings.Builder
+	sb.WriteString("CONSTRAINT ")
+	sb.WriteString(quoteIdentifier(constraint.Name))
+
+	switch constraint.Type {
+	case _primaryKeyConstraint:
+		sb.WriteString(" PRIMARY KEY (")
+	case _uniqueConstraint:
+		sb.WriteString(" UNIQUE (")
+	case _foreignKeyConstraint:
+		sb.WriteString(" FOREIGN KEY (")
+	case _checkConstraint:
+		sb.WriteString(" CHECK (")
+		sb.WriteString(constraint.Check)
+		sb.WriteString(")")
+
+		return sb.String()
+	}
+
+	sb.WriteString(quoteIdentifiers(constraint.Columns))
+	sb.WriteString(")")
+
+	if constraint.Type == _foreignKeyConstraint {
+		sb.WriteString(" REFERENCES ")
+		sb.WriteString(qualifiedIdentifier(constraint.RefSchema, constraint.RefTable))
+		sb.WriteString(" (")
+		sb.WriteString(quoteIdentifiers(constraint.RefColumns))
+		sb.WriteString(")")
+	}
+
+	return sb.String()
+}
+
+// missingCheck reports if the constraint is a check whose expression is not in
+// the model, such as in schema files written before it was introspected
+func missingCheck(constraint *PgConstraint) bool {
+	return constraint.Type == _checkConstraint && strIsEmpty(constraint.Check)
+}
+
+func sqlCreateTable(schema string, table *PgTable) string {
+	var sb strings.Builder
+	sb.WriteString("CREATE TABLE ")
+	sb.WriteString(qualifiedIdentifier(schema, table.Name))
+	sb.WriteString(" (")
+
+	var elements []string
+	for i := range table.Columns {
+		elements = append(elements, sqlColumnDefinition(table.Name, &table.Columns[i]))
+	}
+
+	for i := range table.Constraints {
+		constraint := &table.Constraints[i]
+		if constraint.Type != _foreignKeyConstraint && !missingCheck(constraint) {
+			elements = append(elements, sqlConstraintDefinition(constraint))
+		}
+	}
+
+	for _, element := range elements {
+		sb.WriteString("\n    ")
+		sb.WriteString(element)
+		sb.WriteString(",")
+	}
+
+	return strings.TrimSuffix(sb.String(), ",") + "\n)"
+}
+
+// dependencyOrder sorts the tables so the tables referenced by foreign keys
+// come before the tables referencing them
+func dependencyOrder(changes []SchemaChange) []SchemaChange {
+	byName := make(map[relationName]SchemaChange, len(changes))
+	for _, change := range changes {
+		byName[relationName{Schema: change.schema, Name: change.table().Name}] = change
+	}
+
+	var ordered []SchemaChange
+	visited := make(map[relationName]bool, len(changes))
+
+	var visit func(change SchemaChange)
+	visit = func(change SchemaChange) {
+		name := relationName{Schema: change.schema, Name: change.table().Name}
+		if visited[name] {
+			return
+		}
+
+		visited[name] = true
+		for _, constraint := range change.table().Constraints {
+			if parent, ok := byName[relationName{Schema: constraint.RefSchema, Name: constraint.RefTable}]; ok && constraint.Type == _foreignKeyConstraint {
+				visit(parent)
+			}
+		}
+
+		ordered = append(ordered, change)
+	}
+
+	for _, change := range changes {
+		visit(change)
+	}
+
+	return ordered
+}
+
+func (c *SchemaChange) table() *PgTable {
+	if c.toTable != nil {
+		return c.toTable
+	}
+
+	return c.fromTable
+}
+
+// sqlEnumAddValues returns the statements adding the new enum values, or false
+// when values were removed or reordered, which can not be done by ALTER TYPE
+func sqlEnumAddValues(name string, from, to []string) ([]string, bool) {
+	remaining := slices.DeleteFunc(slices.Clone(to), func(value string) bool {
+		return !slices.Contains(from, value)
+	})
+
+	if !slices.Equal(remaining, from) {
+		return nil, false
+	}
+
+	var statements []string
+	for i, value := range to {
+		if slices.Contains(from, value) {
+			continue
+		}
+
+		var position string
+		switch {
+		case i > 0:
+			position = " AFTER " + quoteLiteral(to[i-1])
+		case len(from) > 0:
+			position = " BEFORE " + quoteLiteral(from[0])
+		}
+
+		statements = append(statements, "ALTER TYPE "+name+" ADD VALUE "+quoteLiteral(value)+position)
+	}
+
+	return statements, true
+}
+
+// migrationStatements returns the SQL statements applying the changes, ordered
+// so objects are dropped before the objects they depend on and created after
+// them. Changes that can not be expressed from the model, such as creating a
+// view, are returned as comments to be handled by hand
+func migrationStatements(changes []SchemaChange) []string {
+	var createEnums, dropViews, dropForeignKeys, dropConstraints, alterColumns, addConstraints, addForeignKeys, dropEnums, manual []string
+	var createdTables, droppedTables []SchemaChange
+	recreatedViews := make(map[string]bool)
+	alteredTypes := make(map[string]bool)
+
+	for _, change := range changes {
+		table := change.table()
+
+		var tableName string
+		if table != nil {
+			tableName = qualifiedIdentifier(change.schema, table.Name)
+		}
+
+		switch {
+		case change.Kind == _enumObject && change.Action == _addedObject:
+			values := make([]string, len(change.toEnum.Values))
+			for i, value := range change.toEnum.Values {
+				values[i] = quoteLiteral(value)
+			}
+
+			createEnums = append(createEnums, "CREATE TYPE "+qualifiedIdentifier(change.schema, change.toEnum.Name)+" AS ENUM ("+strings.Join(values, ", ")+")")
+
+		case change.Kind == _enumObject && change.Action == _removedObject:
+			dropEnums = append(dropEnums, "DROP TYPE "+qualifiedIdentifier(change.schema, change.fromEnum.Name))
+
+		case change.Kind == _enumObject:
+			statements, ok := sqlEnumAddValues(qualifiedIdentifier(change.schema, change.toEnum.Name), change.fromEnum.Values, change.toEnum.Values)
+			if !ok {
+				manual = append(manual, fmt.Sprintf("values of enum [%s] were removed or reordered from [%s] to [%s], the type must be recreated by hand", change.Name, change.From, change.To))
+			}
+
+			createEnums = append(createEnums, statements...)
+
+		case table != nil && table.Kind == _view:
+			switch {
+			case change.Action == _removedObject:
+				dropViews = append(dropViews, "DROP VIEW "+tableName)
+			case change.Action == _addedObject:
+				manual = append(manual, fmt.Sprintf("view [%s] must be created by hand, view definitions are not part of the model", change.Name))
+			case !recreatedViews[tableName]:
+				recreatedViews[tableName] = true
+				manual = append(manual, fmt.Sprintf("view [%s.%s] changed and must be recreated by hand, view definitions are not part of the model", change.schema, table.Name))
+			}
+
+		case change.Kind == _table && change.Action == _addedObject:
+			createdTables = append(createdTables, change)
+			for i := range table.Constraints {
+				constraint := &table.Constraints[i]
+				switch {
+				case constraint.Type == _foreignKeyConstraint:
+					addForeignKeys = append(addForeignKeys, "ALTER TABLE "+tableName+" ADD "+sqlConstraintDefinition(constraint))
+				case missingCheck(constraint):
+					manual = append(manual, fmt.Sprintf("check constraint [%s.%s] has no expression in the model and must be added by hand", change.Name, constraint.Name))
+				}
+			}
+
+		case change.Kind == _table:
+			droppedTables = append(droppedTables, change)
+
+		case change.Kind == _columnObject && change.Action == _addedObject:
+			alterColumns = append(alterColumns, "ALTER TABLE "+tableName+" ADD COLUMN "+sqlColumnDefinition(table.Name, change.toColumn))
+
+		case change.Kind == _columnObject && change.Action == _removedObject:
+			alterColumns = append(alterColumns, "ALTER TABLE "+tableName+" DROP COLUMN "+quoteIdentifier(change.fromColumn.Name))
+
+		case change.Kind == _columnObject && slices.Contains([]string{"type", "max_length", "precision", "scale"}, change.Field):
+			// A column whose type and modifiers changed together is altered once
+			if alteredTypes[change.Name] {
+				continue
+			}
+
+			alteredTypes[change.Name] = true
+			column := quoteIdentifier(change.toColumn.Name)
+			sqlDataType := change.toColumn.sqlType()
+			alterColumns = append(alterColumns, "ALTER TABLE "+tableName+" ALTER COLUMN "+column+" TYPE "+sqlDataType+" USING "+column+"::"+sqlDataType)
+
+		case change.Kind == _columnObject && change.Field == "default":
+			action := " DROP DEFAULT"
+			if !strIsEmpty(change.toColumn.Default) {
+				action = " SET DEFAULT " + change.toColumn.Default
+			}
+
+			alterColumns = append(alterColumns, "ALTER TABLE "+tableName+" ALTER COLUMN "+quoteIdentifier(change.toColumn.Name)+action)
+
+		case change.Kind == _columnObject && change.Field == "nullable":
+			action := " SET NOT NULL"
+			if change.toColumn.Nullable {
+				action = " DROP NOT NULL"
+			}
+
+			alterColumns = append(alterColumns, "ALTER TABLE "+tableName+" ALTER COLUMN "+quoteIdentifier(change.toColumn.Name)+action)
+
+		case change.Kind == _constraintObject:
+			if change.fromConstraint != nil {
+				statement := "ALTER TABLE " + tableName + " DROP CONSTRAINT " + quoteIdentifier(change.fromConstraint.Name)
+				if change.fromConstraint.Type == _foreignKeyConstraint {
+					dropForeignKeys = append(dropForeignKeys, statement)
+				} else {
+					dropConstraints = append(dropConstraints, statement)
+				}
+			}
+
+			if change.toConstraint == nil {
+				continue
+			}
+
+			switch {
+			case change.toConstraint.Type == _foreignKeyConstraint:
+				addForeignKeys = append(addForeignKeys, "ALTER TABLE "+tableName+" ADD "+sqlConstraintDefinition(change.toConstraint))
+			case missingCheck(change.toConstraint):
+				manual = append(manual, fmt.Sprintf("check constraint [%s] has no expression in the model and must be added by hand", change.Name))
+			default:
+				addConstraints = append(addConstraints, "ALTER TABLE "+tableName+" ADD "+sqlConstraintDefinition(change.toConstraint))
+			}
+		}
+	}
+
+	var createTables, dropTables []string
+	for _, change := range dependencyOrder(createdTables) {
+		createTables = append(createTables, sqlCreateTable(change.schema, change.toTable))
+	}
+
+	orderedDrops := dependencyOrder(droppedTables)
+	slices.Reverse(orderedDrops)
+	for _, change := range orderedDrops {
+		dropTables = append(dropTables, "DROP TABLE "+qualifiedIdentifier(change.schema, change.fromTable.Name))
+	}
+
+	var statements []string
+	for _, statement := range slices.Concat(createEnums, dropViews, dropForeignKeys, dropConstraints, dropTables, createTables, alterColumns, addConstraints, addForeignKeys, dropEnums) {
+		statements = append(statements, statement+";")
+	}
+
+	for _, note := range manual {
+		statements = append(statements, "-- TODO: "+note)
+	}
+
+	return statements
+}
+
+func MigrationFile(path, header string, changes []SchemaChange) GeneratedFile {
+	var sb strings.Builder
+	sb.WriteString("-- ")
+	sb.WriteString(header)
+	sb.WriteString("\n")
+
+	statements := migrationStatements(changes)
+	if len(statements) == 0 {
+		sb.WriteString("-- No changes\n")
+	}
+
+	for _, statement := range statements {
+		sb.WriteString("\n")
+		sb.WriteString(statement)
+		sb.WriteString("\n")
+	}
+
+	return GeneratedFile{
+		Path:    path,
+		Content: []byte(sb.String()),
+		Source:  header,
+	}
+}
@@ -0,0 +1,74 @@
+package pggen
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMigrationStatementsCheckConstraints(t *testing.T) {
+	users := func(constraints ...PgConstraint) []PgSchema {
+		return []PgSchema{{
+			Name: "public",
+			Tables: []PgTable{{
+				Kind:        _table,
+				Name:        "users",
+				Columns:     []PgColumn{{Name: "age", SqlDataType: "INT4"}},
+				Constraints: constraints,
+			}},
+		}}
+	}
+
+	ageCheck := func(check string) PgConstraint {
+		return PgConstraint{Name: "users_age_check", Type: _checkConstraint, Columns: []string{"age"}, Check: check}
+	}
+
+	tests := []struct {
+		name     string
+		from     []PgSchema
+		to       []PgSchema
+		expected []string
+	}{
+		{
+			name: "created table",
+			from: []PgSchema{{Name: "public"}},
+			to:   users(ageCheck("age >= 18")),
+			expected: []string{
+				"CREATE TABLE \"public\".\"users\" (\n    \"age\" int4 NOT NULL,\n    CONSTRAINT \"users_age_check\" CHECK (age >= 18)\n);",
+			},
+		},
+		{
+			name: "added check",
+			from: users(),
+			to:   users(ageCheck("age >= 18")),
+			expected: []string{
+				"ALTER TABLE \"public\".\"users\" ADD CONSTRAINT \"users_age_check\" CHECK (age >= 18);",
+			},
+		},
+		{
+			name: "changed check",
+			from: users(ageCheck("age >= 18")),
+			to:   users(ageCheck("age >= 21")),
+			expected: []string{
+				"ALTER TABLE \"public\".\"users\" DROP CONSTRAINT \"users_age_check\";",
+				"ALTER TABLE \"public\".\"users\" ADD CONSTRAINT \"users_age_check\" CHECK (age >= 21);",
+			},
+		},
+		{
+			name: "check without expression",
+			from: users(),
+			to:   users(ageCheck("")),
+			expected: []string{
+				"-- TODO: check constraint [public.users.users_age_check] has no expression in the model and must be added by hand",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			statements := migrationStatements(DiffSchemas(test.from, test.to))
+			if !reflect.DeepEqual(statements, test.expected) {
+				t.Errorf("expected statements %q, got %q", test.expected, statements)
+			}
+		})
+	}
+}
@@ -0,0 +1,1772 @@
+package pggen
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const _defaultSearchPath = "public"
+
+// _ignoredStatements do not change the tables, views and enums read from the
+// migrations, so they are skipped without warnings
+var _ignoredStatements = [][]string{
+	{"insert"}, {"update"}, {"delete"}, {"select"}, {"with"}, {"truncate"}, {"copy"},
+	{"begin"}, {"start"}, {"commit"}, {"end"}, {"rollback"}, {"savepoint"}, {"release"},
+	{"grant"}, {"revoke"}, {"analyze"}, {"vacuum"}, {"reindex"}, {"refresh"},
+	{"create", "extension"}, {"create", "schema"},
+	{"create", "sequence"}, {"create", "trigger"}, {"create", "or", "replace", "trigger"},
+	{"alter", "sequence"}, {"alter", "extension"},
+	{"alter", "function"}, {"alter", "procedure"},
+	{"drop", "sequence"}, {"drop", "trigger"}, {"drop", "extension"},
+	{"drop", "function"}, {"drop", "procedure"},
+}
+
+// _viewFunctionTypes are the result types of common functions used in views
+var _viewFunctionTypes = map[string]string{
+	"count":           "INT8",
+	"now":             "TIMESTAMPTZ",
+	"lower":           "TEXT",
+	"upper":           "TEXT",
+	"concat":          "TEXT",
+	"gen_random_uuid": "UUID",
+}
+
+var _fromClauseEndKeywords = []string{
+	"where", "group", "having", "window", "order", "limit", "offset",
+	"fetch", "for", "union", "intersect", "except",
+}
+
+var _joinKeywords = []string{
+	"join", "inner", "left", "right", "full", "outer", "cross", "natural",
+	"on", "using", "lateral", "tablesample",
+}
+
+type relationName struct {
+	Schema string
+	Name   string
+}
+
+func (r relationName) String() string {
+	return r.Schema + "." + r.Name
+}
+
+type viewSource struct {
+	Alias string
+	Table *PgTable
+}
+
+// migrationCatalog holds the tables, views and enums built by applying the
+// DDL statements of the migrations in order
+type migrationCatalog struct {
+	searchPath string
+	relations  map[relationName]*PgTable
+	enums      map[relationName]*PgEnum
+	statement  *sqlStatement
+}
+
+func newMigrationCatalog() *migrationCatalog {
+	return &migrationCatalog{
+		searchPath: _defaultSearchPath,
+		relations:  make(map[relationName]*PgTable),
+		enums:      make(map[relationName]*PgEnum),
+	}
+}
+
+func (mc *migrationCatalog) warn(format string, args ...any) {
+	log.Printf("- Warning [%s] %s\n", mc.statement.location(), fmt.Sprintf(format, args...))
+}
+
+func migrationFiles(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations [%s], got error [%s]", path, err.Error())
+	}
+
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	files, err := filepath.Glob(filepath.Join(path, "*.sql"))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no .sql files found in migrations directory [%s]", path)
+	}
+
+	return files, nil
+}
+
+// loadMigrations builds the model by parsing the DDL of the migration files in
+// place of the database, the files of a directory are applied in name order
+func (pcg *PgCodeGenerator) loadMigrations(path string) ([]PgSchema, error) {
+	files, err := migrationFiles(path)
+	if err != nil {
+		return nil, err
+	}
+
+	catalog := newMigrationCatalog()
+	for _, file := range files {
+		if err := catalog.applyFile(file); err != nil {
+			return nil, err
+		}
+	}
+
+	var schemas []PgSchema
+	for _, schemaName := range pcg.schemaNames() {
+		schemas = append(schemas, catalog.schema(schemaName, pcg.cfg.Schemas[schemaName]))
+	}
+
+	return schemas, nil
+}
+
+func (mc *migrationCatalog) schema(schemaName string, schema ConfigSchema) PgSchema {
+	var names []relationName
+	for name := range mc.relations {
+		if name.Schema == schemaName {
+			names = append(names, name)
+		}
+	}
+
+	slices.SortFunc(names, func(a, b relationName) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	var tables, views []PgTable
+	for _, name := range names {
+		table := *cloneTable(mc.relations[name])
+		slices.SortFunc(table.Constraints, func(a, b PgConstraint) int {
+			return strings.Compare(a.Name, b.Name)
+		})
+
+		if table.Kind == _view {
+			views = append(views, table)
+		} else {
+			tables = append(tables, table)
+		}
+	}
+
+	if schema.IncludeViews {
+		tables = append(tables, views...)
+	}
+
+	var enums []PgEnum
+	for name, enum := range mc.enums {
+		if name.Schema == schemaName {
+			enums = append(enums, PgEnum{Name: enum.Name, Values: slices.Clone(enum.Values)})
+		}
+	}
+
+	slices.SortFunc(enums, func(a, b PgEnum) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return PgSchema{
+		Name:   schemaName,
+		Tables: tables,
+		Enums:  enums,
+	}
+}
+
+func (mc *migrationCatalog) applyFile(path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read file [%s]", path)
+	}
+
+	tokens, err := tokenizeSQL(string(content))
+	if err != nil {
+		return fmt.Errorf("failed to parse file [%s], got error [%s]", path, err.Error())
+	}
+
+	for _, statement := range splitStatements(path, tokens) {
+		mc.statement = &statement
+		if err := mc.applyStatement(&ddlParser{tokens: statement.Tokens}); err != nil {
+			mc.warn("statement [%s] was skipped, got error [%s]", statement.label(), err.Error())
+		}
+	}
+
+	return nil
+}
+
+func (mc *migrationCatalog) applyStatement(p *ddlParser) error {
+	for _, keywords := range _ignoredStatements {
+		if p.isKeyword(keywords...) {
+			return nil
+		}
+	}
+
+	switch {
+	case p.acceptKeyword("create", "or", "replace", "view"):
+		return mc.createView(p, true)
+	case p.acceptKeyword("create", "view"):
+		return mc.createView(p, false)
+	case p.acceptKeyword("create", "table"), p.acceptKeyword("create", "unlogged", "table"):
+		return mc.createTable(p)
+	case p.acceptKeyword("create", "type"):
+		return mc.createType(p)
+	case p.acceptKeyword("alter", "table"):
+		return mc.alterTable(p, _table)
+	case p.acceptKeyword("alter", "view"):
+		return mc.alterTable(p, _view)
+	case p.acceptKeyword("alter", "type"):
+		return mc.alterType(p)
+	case p.acceptKeyword("drop", "table"):
+		return mc.dropRelations(p, _table)
+	case p.acceptKeyword("drop", "view"):
+		return mc.dropRelations(p, _view)
+	case p.acceptKeyword("drop", "type"):
+		return mc.dropTypes(p)
+	case p.acceptKeyword("create", "index"):
+		return mc.createIndex(p, false)
+	case p.acceptKeyword("create", "unique", "index"):
+		return mc.createIndex(p, true)
+	case p.acceptKeyword("alter", "index"):
+		return mc.alterIndex(p)
+	case p.acceptKeyword("drop", "index"):
+		return mc.dropIndexes(p)
+	case p.acceptKeyword("comment", "on"):
+		return mc.comment(p)
+	case p.acceptKeyword("set"):
+		return mc.setSearchPath(p)
+	}
+
+	mc.warn("unsupported statement [%s] was skipped", mc.statement.label())
+
+	return nil
+}
+
+func (mc *migrationCatalog) setSearchPath(p *ddlParser) error {
+	p.acceptKeyword("session")
+	p.acceptKeyword("local")
+	if !p.acceptKeyword("search_path") {
+		return nil
+	}
+
+	if !p.acceptKeyword("to") && !p.acceptSymbol("=") {
+		return fmt.Errorf("expected [TO] or [=], got [%s]", p.peek())
+	}
+
+	for _, item := range splitList(p.rest()) {
+		if len(item) == 1 && item[0].Text != "$user" && !strIsEmpty(item[0].Text) {
+			mc.searchPath = item[0].Text
+			return nil
+		}
+	}
+
+	return nil
+}
+
+func (mc *migrationCatalog) createTable(p *ddlParser) error {
+	ifNotExists := p.acceptKeyword("if", "not", "exists")
+	name, err := p.qualifiedName(mc.searchPath)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := mc.relations[name]; ok {
+		if ifNotExists {
+			return nil
+		}
+
+		return fmt.Errorf("relation [%s] already exists", name)
+	}
+
+	if !p.peek().isSymbol("(") {
+		return fmt.Errorf("table [%s] must be created with a column list", name)
+	}
+
+	elements, err := p.group()
+	if err != nil {
+		return err
+	}
+
+	table := &PgTable{
+		Kind: _table,
+		Name: name.Name,
+	}
+
+	for _, element := range splitList(elements) {
+		if err := mc.tableElement(&ddlParser{tokens: element}, table); err != nil {
+			return err
+		}
+	}
+
+	if p.isKeyword("inherits") || p.isKeyword("partition", "of") {
+		mc.warn("inheritance of table [%s] is not supported, only its own columns were read", name)
+	}
+
+	mc.resolveReferences(name, table)
+	mc.relations[name] = table
+
+	return nil
+}
+
+func isTableConstraint(p *ddlParser) bool {
+	if p.isKeyword("exclude") && len(p.rest()) > 1 {
+		following := p.rest()[1]
+		return following.isSymbol("(") || following.isKeyword("using")
+	}
+
+	return p.isKeyword("constraint") ||
+		p.isKeyword("primary", "key") ||
+		p.isKeyword("unique") ||
+		p.isKeyword("foreign", "key") ||
+		p.isKeyword("check")
+}
+
+func (mc *migrationCatalog) tableElement(p *ddlParser, table *PgTable) error {
+	if isTableConstraint(p) {
+		return mc.tableConstraint(p, table)
+	}
+
+	if p.isKeyword("like") {
+		return errors.New("LIKE table elements are not supported")
+	}
+
+	return mc.addColumn(p, table)
+}
+
+func (mc *migrationCatalog) addColumn(p *ddlParser, table *PgTable) error {
+	columnName, err := p.identifier()
+	if err != nil {
+		return err
+	}
+
+	if table.column(columnName) != nil {
+		return fmt.Errorf("column [%s] of table [%s] already exists", columnName, table.Name)
+	}
+
+	dataType, err := p.dataType()
+	if err != nil {
+		return err
+	}
+
+	table.Columns = append(table.Columns, PgColumn{
+		Name:        columnName,
+		SqlDataType: dataType.Name,
+		GoDataType:  goDataType(dataType.Name),
+		MaxLength:   dataType.MaxLength,
+		Precision:   dataType.Precision,
+		Scale:       dataType.Scale,
+		Nullable:    !dataType.Serial,
+	})
+	column := &table.Columns[len(table.Columns)-1]
+
+	if dataType.Serial {
+		column.Default = "nextval('" + table.Name + "_" + columnName + "_seq'::regclass)"
+	}
+
+	for !p.done() {
+		var constraintName string
+		if p.acceptKeyword("constraint") {
+			constraintName, err = p.identifier()
+			if err != nil {
+				return err
+			}
+		}
+
+		switch {
+		case p.acceptKeyword("not", "null"):
+			column.Nullable = false
+
+		case p.acceptKeyword("null"):
+			column.Nullable = true
+
+		case p.acceptKeyword("default"):
+			start := p.pos
+			if err := p.skipExpression(); err != nil {
+				return err
+			}
+
+			column.Default = sqlText(p.tokens[start:p.pos])
+
+		case p.acceptKeyword("generated"):
+			if err := p.skipGenerated(column); err != nil {
+				return err
+			}
+
+		case p.acceptKeyword("collate"):
+			if _, err := p.qualifiedName(""); err != nil {
+				return err
+			}
+
+		default:
+			constraint, err := mc.constraint(p, table, []string{columnName})
+			if err != nil {
+				return err
+			}
+
+			if err := mc.addConstraint(table, constraintName, constraint); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (p *ddlParser) skipGenerated(column *PgColumn) error {
+	if !p.acceptKeyword("always") && !p.acceptKeyword("by", "default") {
+		return fmt.Errorf("expected [ALWAYS] or [BY DEFAULT], got [%s]", p.peek())
+	}
+
+	if err := p.expectKeyword("as"); err != nil {
+		return err
+	}
+
+	if p.acceptKeyword("identity") {
+		column.Nullable = false
+		if p.peek().isSymbol("(") {
+			_, err := p.group()
+			return err
+		}
+
+		return nil
+	}
+
+	if _, err := p.group(); err != nil {
+		return err
+	}
+
+	p.acceptKeyword("stored")
+
+	return nil
+}
+
+func (mc *migrationCatalog) tableConstraint(p *ddlParser, table *PgTable) error {
+	var constraintName string
+	if p.acceptKeyword("constraint") {
+		name, err := p.identifier()
+		if err != nil {
+			return err
+		}
+
+		constraintName = name
+	}
+
+	constraint, err := mc.constraint(p, table, nil)
+	if err != nil {
+		return err
+	}
+
+	if !p.done() {
+		return fmt.Errorf("unexpected [%s] in constraint of table [%s]", p.peek(), table.Name)
+	}
+
+	return mc.addConstraint(table, constraintName, constraint)
+}
+
+// constraint parses a column or table constraint, columns holds the column of
+// a column constraint and is nil for table constraints
+func (mc *migrationCatalog) constraint(p *ddlParser, table *PgTable, columns []string) (PgConstraint, error) {
+	constraint := PgConstraint{
+		Columns: columns,
+	}
+
+	var err error
+	switch {
+	case p.acceptKeyword("primary", "key"):
+		constraint.Type = _primaryKeyConstraint
+		if columns == nil {
+			constraint.Columns, err = p.identifierList()
+		}
+
+	case p.acceptKeyword("unique"):
+		constraint.Type = _uniqueConstraint
+		if !p.acceptKeyword("nulls", "distinct") {
+			p.acceptKeyword("nulls", "not", "distinct")
+		}
+
+		if columns == nil {
+			constraint.Columns, err = p.identifierList()
+		}
+
+	case p.isKeyword("references"), p.isKeyword("foreign", "key"):
+		constraint.Type = _foreignKeyConstraint
+		if p.acceptKeyword("foreign", "key") {
+			constraint.Columns, err = p.identifierList()
+			if err != nil {
+				return PgConstraint{}, err
+			}
+		}
+
+		if err := p.expectKeyword("references"); err != nil {
+			return PgConstraint{}, err
+		}
+
+		ref, err := p.qualifiedName(mc.searchPath)
+		if err != nil {
+			return PgConstraint{}, err
+		}
+
+		constraint.RefSchema = ref.Schema
+		constraint.RefTable = ref.Name
+		if p.peek().isSymbol("(") {
+			constraint.RefColumns, err = p.identifierList()
+		}
+
+	case p.acceptKeyword("check"):
+		constraint.Type = _checkConstraint
+
+		var expression []sqlToken
+		expression, err = p.group()
+		constraint.Check = sqlText(expression)
+		if columns == nil {
+			constraint.Columns = checkColumns(table, expression)
+		}
+
+	case p.isKeyword("exclude"):
+		return PgConstraint{}, errors.New("EXCLUDE constraints are not supported")
+
+	default:
+		return PgConstraint{}, fmt.Errorf("unexpected [%s] in table [%s]", p.peek(), table.Name)
+	}
+
+	if err != nil {
+		return PgConstraint{}, err
+	}
+
+	return constraint, p.skipConstraintOptions()
+}
+
+func (p *ddlParser) skipConstraintOptions() error {
+	for {
+		switch {
+		case p.acceptKeyword("deferrable"),
+			p.acceptKeyword("not", "deferrable"),
+			p.acceptKeyword("not", "valid"),
+			p.acceptKeyword("no", "inherit"):
+
+		case p.acceptKeyword("initially"), p.acceptKeyword("match"), p.acceptKeyword("using", "index", "tablespace"):
+			p.next()
+
+		case p.acceptKeyword("include"), p.acceptKeyword("with"):
+			if _, err := p.group(); err != nil {
+				return err
+			}
+
+		case p.acceptKeyword("on", "delete"), p.acceptKeyword("on", "update"):
+			switch {
+			case p.acceptKeyword("set"):
+				p.next()
+				if p.peek().isSymbol("(") {
+					if _, err := p.group(); err != nil {
+						return err
+					}
+				}
+
+			case p.acceptKeyword("no", "action"):
+
+			default:
+				p.next()
+			}
+
+		default:
+			return nil
+		}
+	}
+}
+
+// checkColumns returns the table columns referenced by a check expression
+func checkColumns(table *PgTable, expression []sqlToken) []string {
+	var columns []string
+	for _, column := range table.Columns {
+		referenced := slices.ContainsFunc(expression, func(token sqlToken) bool {
+			return token.Kind == _identifierToken && token.Text == column.Name
+		})
+
+		if referenced {
+			columns = append(columns, column.Name)
+		}
+	}
+
+	return columns
+}
+
+// defaultConstraintName mimics the names Postgres chooses for unnamed constraints
+func defaultConstraintName(table *PgTable, constraint PgConstraint) string {
+	columns := constraint.Columns
+
+	var suffix string
+	switch constraint.Type {
+	case _primaryKeyConstraint:
+		suffix, columns = "pkey", nil
+	case _uniqueConstraint:
+		suffix = "key"
+	case _foreignKeyConstraint:
+		suffix = "fkey"
+	default:
+		suffix = "check"
+		if len(columns) != 1 {
+			columns = nil
+		}
+	}
+
+	parts := append([]string{table.Name}, columns...)
+	base := strings.Join(append(parts, suffix), "_")
+
+	name := base
+	for i := 1; table.constraint(name) != nil; i++ {
+		name = base + strconv.Itoa(i)
+	}
+
+	return name
+}
+
+func (mc *migrationCatalog) addConstraint(table *PgTable, name string, constraint PgConstraint) error {
+	for _, column := range constraint.Columns {
+		if table.column(column) == nil {
+			return fmt.Errorf("column [%s] of table [%s] does not exist", column, table.Name)
+		}
+	}
+
+	if constraint.Type == _primaryKeyConstraint {
+		if table.primaryKey() != nil {
+			return fmt.Errorf("table [%s] already has a primary key", table.Name)
+		}
+
+		for _, columnName := range constraint.Columns {
+			column := table.column(columnName)
+			column.IsPrimaryKey = true
+			column.Nullable = false
+		}
+	}
+
+	constraint.Name = name
+	if strIsEmpty(name) {
+		constraint.Name = defaultConstraintName(table, constraint)
+	} else if table.constraint(name) != nil {
+		return fmt.Errorf("constraint [%s] of table [%s] already exists", name, table.Name)
+	}
+
+	table.Constraints = append(table.Constraints, constraint)
+
+	return nil
+}
+
+// resolveReferences checks the foreign keys of the table, filling the
+// referenced columns left out by the statement with the referenced primary key
+func (mc *migrationCatalog) resolveReferences(name relationName, table *PgTable) {
+	for i := range table.Constraints {
+		constraint := &table.Constraints[i]
+		if constraint.Type != _foreignKeyConstraint {
+			continue
+		}
+
+		ref := relationName{Schema: constraint.RefSchema, Name: constraint.RefTable}
+		refTable := mc.relations[ref]
+		if ref == name {
+			refTable = table
+		}
+
+		if refTable == nil {
+			mc.warn("foreign key [%s] of table [%s] references the unknown table [%s]", constraint.Name, name, ref)
+			continue
+		}
+
+		if constraint.RefColumns != nil {
+			continue
+		}
+
+		primaryKey := refTable.primaryKey()
+		if primaryKey == nil {
+			mc.warn("foreign key [%s] of table [%s] references table [%s], which has no primary key", constraint.Name, name, ref)
+			continue
+		}
+
+		constraint.RefColumns = slices.Clone(primaryKey.Columns)
+	}
+}
+
+func cloneTable(table *PgTable) *PgTable {
+	clone := *table
+	clone.Columns = slices.Clone(table.Columns)
+	clone.Constraints = make([]PgConstraint, len(table.Constraints))
+
+	for i, constraint := range table.Constraints {
+		constraint.Columns = slices.Clone(constraint.Columns)
+		constraint.RefColumns = slices.Clone(constraint.RefColumns)
+		clone.Constraints[i] = constraint
+	}
+
+	clone.Indexes = make([]PgIndex, len(table.Indexes))
+	for i, index := range table.Indexes {
+		index.Columns = slices.Clone(index.Columns)
+		clone.Indexes[i] = index
+	}
+
+	return &clone
+}
+
+// references returns the foreign keys referencing the relation, table holds
+// the relation being altered in place of its catalog entry
+func (mc *migrationCatalog) references(name relationName, table *PgTable) []*PgConstraint {
+	var references []*PgConstraint
+	for relation, other := range mc.relations {
+		if relation == name {
+			other = table
+		}
+
+		if other == nil {
+			continue
+		}
+
+		for i := range other.Constraints {
+			constraint := &other.Constraints[i]
+			if constraint.Type == _foreignKeyConstraint && constraint.RefSchema == name.Schema && constraint.RefTable == name.Name {
+				references = append(references, constraint)
+			}
+		}
+	}
+
+	return references
+}
+
+// dropReferences drops the foreign keys referencing the relation, or only the
+// ones referencing the column when it is not blank
+func (mc *migrationCatalog) dropReferences(name relationName, table *PgTable, column string) {
+	for relation, other := range mc.relations {
+		if relation == name {
+			other = table
+		}
+
+		if other == nil {
+			continue
+		}
+
+		other.Constraints = slices.DeleteFunc(other.Constraints, func(constraint PgConstraint) bool {
+			return constraint.Type == _foreignKeyConstraint &&
+				constraint.RefSchema == name.Schema &&
+				constraint.RefTable == name.Name &&
+				(strIsEmpty(column) || slices.Contains(constraint.RefColumns, column))
+		})
+	}
+}
+
+func (mc *migrationCatalog) alterTable(p *ddlParser, kind string) error {
+	ifExists := p.acceptKeyword("if", "exists")
+	p.acceptKeyword("only")
+
+	name, err := p.qualifiedName(mc.searchPath)
+	if err != nil {
+		return err
+	}
+
+	p.acceptSymbol("*")
+
+	current, ok := mc.relations[name]
+	if !ok || current.Kind != kind {
+		if ifExists {
+			return nil
+		}
+
+		return fmt.Errorf("%s [%s] does not exist", kind, name)
+	}
+
+	if p.acceptKeyword("rename", "to") {
+		newName, err := p.identifier()
+		if err != nil {
+			return err
+		}
+
+		return mc.renameRelation(name, relationName{Schema: name.Schema, Name: newName})
+	}
+
+	table := cloneTable(current)
+	for _, action := range splitList(p.rest()) {
+		if err := mc.alterTableAction(&ddlParser{tokens: action}, name, table); err != nil {
+			return err
+		}
+	}
+
+	mc.resolveReferences(name, table)
+	mc.relations[name] = table
+
+	return nil
+}
+
+func (mc *migrationCatalog) alterTableAction(p *ddlParser, name relationName, table *PgTable) error {
+	switch {
+	case p.acceptKeyword("add"):
+		if isTableConstraint(p) {
+			return mc.tableConstraint(p, table)
+		}
+
+		p.acceptKeyword("column")
+		if p.acceptKeyword("if", "not", "exists") && table.column(p.peek().Text) != nil {
+			return nil
+		}
+
+		return mc.addColumn(p, table)
+
+	case p.acceptKeyword("drop", "constraint"):
+		ifExists := p.acceptKeyword("if", "exists")
+		constraintName, err := p.identifier()
+		if err != nil {
+			return err
+		}
+
+		constraint := table.constraint(constraintName)
+		if constraint == nil {
+			if ifExists {
+				return nil
+			}
+
+			return fmt.Errorf("constraint [%s] of table [%s] does not exist", constraintName, name)
+		}
+
+		if constraint.Type == _primaryKeyConstraint {
+			for _, columnName := range constraint.Columns {
+				table.column(columnName).IsPrimaryKey = false
+			}
+		}
+
+		table.Constraints = slices.DeleteFunc(table.Constraints, func(c PgConstraint) bool {
+			return c.Name == constraintName
+		})
+
+		return nil
+
+	case p.acceptKeyword("drop"):
+		p.acceptKeyword("column")
+		ifExists := p.acceptKeyword("if", "exists")
+		columnName, err := p.identifier()
+		if err != nil {
+			return err
+		}
+
+		if table.column(columnName) == nil {
+			if ifExists {
+				return nil
+			}
+
+			return fmt.Errorf("column [%s] of table [%s] does not exist", columnName, name)
+		}
+
+		table.Columns = slices.DeleteFunc(table.Columns, func(c PgColumn) bool {
+			return c.Name == columnName
+		})
+
+		table.Constraints = slices.DeleteFunc(table.Constraints, func(c PgConstraint) bool {
+			return slices.Contains(c.Columns, columnName)
+		})
+
+		table.Indexes = slices.DeleteFunc(table.Indexes, func(index PgIndex) bool {
+			return slices.Contains(index.Columns, columnName)
+		})
+
+		mc.dropReferences(name, table, columnName)
+
+		return nil
+
+	case p.acceptKeyword("alter"):
+		p.acceptKeyword("column")
+		columnName, err := p.identifier()
+		if err != nil {
+			return err
+		}
+
+		column := table.column(columnName)
+		if column == nil {
+			return fmt.Errorf("column [%s] of table [%s] does not exist", columnName, name)
+		}
+
+		switch {
+		case p.acceptKeyword("set", "not", "null"):
+			column.Nullable = false
+
+		case p.acceptKeyword("drop", "not", "null"):
+			if column.IsPrimaryKey {
+				return fmt.Errorf("column [%s] of table [%s] is in a primary key", columnName, name)
+			}
+
+			column.Nullable = true
+
+		case p.acceptKeyword("set", "data", "type"), p.acceptKeyword("type"):
+			dataType, err := p.dataType()
+			if err != nil {
+				return err
+			}
+
+			column.SqlDataType = dataType.Name
+			column.GoDataType = goDataType(dataType.Name)
+			column.MaxLength = dataType.MaxLength
+			column.Precision = dataType.Precision
+			column.Scale = dataType.Scale
+
+		case p.acceptKeyword("set", "default"):
+			column.Default = sqlText(p.rest())
+
+		case p.acceptKeyword("drop", "default"):
+			column.Default = ""
+		}
+
+		return nil
+
+	case p.acceptKeyword("rename", "constraint"):
+		oldName, err := p.identifier()
+		if err != nil {
+			return err
+		}
+
+		if err := p.expectKeyword("to"); err != nil {
+			return err
+		}
+
+		newName, err := p.identifier()
+		if err != nil {
+			return err
+		}
+
+		constraint := table.constraint(oldName)
+		if constraint == nil {
+			return fmt.Errorf("constraint [%s] of table [%s] does not exist", oldName, name)
+		}
+
+		constraint.Name = newName
+
+		return nil
+
+	case p.acceptKeyword("rename"):
+		p.acceptKeyword("column")
+		oldName, err := p.identifier()
+		if err != nil {
+			return err
+		}
+
+		if err := p.expectKeyword("to"); err != nil {
+			return err
+		}
+
+		newName, err := p.identifier()
+		if err != nil {
+			return err
+		}
+
+		return mc.renameColumn(name, table, oldName, newName)
+
+	case p.isKeyword("set", "schema"):
+		return errors.New("moving relations between schemas is not supported")
+
+	case p.isKeyword("owner"), p.isKeyword("set"), p.isKeyword("reset"),
+		p.isKeyword("enable"), p.isKeyword("disable"), p.isKeyword("force"),
+		p.isKeyword("no"), p.isKeyword("replica"), p.isKeyword("cluster"),
+		p.isKeyword("validate"), p.isKeyword("inherit"), p.isKeyword("of"),
+		p.isKeyword("not", "of"):
+		return nil
+	}
+
+	return fmt.Errorf("unsupported action [%s] on [%s]", tokensText(p.rest()), name)
+}
+
+func (mc *migrationCatalog) renameColumn(name relationName, table *PgTable, oldName, newName string) error {
+	column := table.column(oldName)
+	if column == nil {
+		return fmt.Errorf("column [%s] of table [%s] does not exist", oldName, name)
+	}
+
+	if table.column(newName) != nil {
+		return fmt.Errorf("column [%s] of table [%s] already exists", newName, name)
+	}
+
+	column.Name = newName
+
+	rename := func(columns []string) {
+		if i := slices.Index(columns, oldName); i >= 0 {
+			columns[i] = newName
+		}
+	}
+
+	for i := range table.Constraints {
+		rename(table.Constraints[i].Columns)
+	}
+
+	for i := range table.Indexes {
+		rename(table.Indexes[i].Columns)
+	}
+
+	for _, reference := range mc.references(name, table) {
+		rename(reference.RefColumns)
+	}
+
+	return nil
+}
+
+func (mc *migrationCatalog) renameRelation(oldName, newName relationName) error {
+	if _, ok := mc.relations[newName]; ok {
+		return fmt.Errorf("relation [%s] already exists", newName)
+	}
+
+	table := mc.relations[oldName]
+	for _, reference := range mc.references(oldName, table) {
+		reference.RefTable = newName.Name
+	}
+
+	delete(mc.relations, oldName)
+	table.Name = newName.Name
+	mc.relations[newName] = table
+
+	return nil
+}
+
+func (mc *migrationCatalog) dropRelations(p *ddlParser, kind string) error {
+	ifExists := p.acceptKeyword("if", "exists")
+
+	for _, item := range splitList(p.rest()) {
+		name, err := (&ddlParser{tokens: item}).qualifiedName(mc.searchPath)
+		if err != nil {
+			return err
+		}
+
+		if relation, ok := mc.relations[name]; !ok || relation.Kind != kind {
+			if ifExists {
+				continue
+			}
+
+			return fmt.Errorf("%s [%s] does not exist", kind, name)
+		}
+
+		delete(mc.relations, name)
+		mc.dropReferences(name, nil, "")
+	}
+
+	return nil
+}
+
+// index returns the table holding the index and its position, indexes are
+// named uniquely within a schema
+func (mc *migrationCatalog) index(name relationName) (*PgTable, int) {
+	for relation, table := range mc.relations {
+		if relation.Schema != name.Schema {
+			continue
+		}
+
+		for i := range table.Indexes {
+			if table.Indexes[i].Name == name.Name {
+				return table, i
+			}
+		}
+	}
+
+	return nil, -1
+}
+
+// indexColumn returns the column or expression of an index element, without
+// its collation, operator class and ordering
+func indexColumn(element []sqlToken) string {
+	if len(element) == 1 || (len(element) > 1 && element[0].Kind == _identifierToken && !element[1].isSymbol("(")) {
+		return element[0].Text
+	}
+
+	p := &ddlParser{tokens: element}
+	if err := p.skipUnit(); err != nil {
+		return sqlText(element)
+	}
+
+	if p.peek().isSymbol("(") {
+		if err := p.skipUnit(); err != nil {
+			return sqlText(element)
+		}
+	}
+
+	expression := element[:p.pos]
+	if expression[0].isSymbol("(") {
+		expression = expression[1 : len(expression)-1]
+	}
+
+	return sqlText(expression)
+}
+
+func (mc *migrationCatalog) createIndex(p *ddlParser, unique bool) error {
+	p.acceptKeyword("concurrently")
+	ifNotExists := p.acceptKeyword("if", "not", "exists")
+
+	var indexName string
+	if !p.isKeyword("on") {
+		name, err := p.identifier()
+		if err != nil {
+			return err
+		}
+
+		indexName = name
+	}
+
+	if err := p.expectKeyword("on"); err != nil {
+		return err
+	}
+
+	p.acceptKeyword("only")
+	name, err := p.qualifiedName(mc.searchPath)
+	if err != nil {
+		return err
+	}
+
+	table, ok := mc.relations[name]
+	if !ok || table.Kind != _table {
+		return fmt.Errorf("table [%s] does not exist", name)
+	}
+
+	index := PgIndex{
+		Name:   indexName,
+		Unique: unique,
+		Method: "btree",
+	}
+
+	if p.acceptKeyword("using") {
+		if index.Method, err = p.identifier(); err != nil {
+			return err
+		}
+	}
+
+	elements, err := p.group()
+	if err != nil {
+		return err
+	}
+
+	for _, element := range splitList(elements) {
+		index.Columns = append(index.Columns, indexColumn(element))
+	}
+
+	for !p.done() {
+		if p.acceptKeyword("where") {
+			index.Where = sqlText(p.rest())
+			break
+		}
+
+		if err := p.skipUnit(); err != nil {
+			return err
+		}
+	}
+
+	if strIsEmpty(index.Name) {
+		var parts []string
+		for _, column := range index.Columns {
+			if table.column(column) == nil {
+				column = "expr"
+			}
+
+			parts = append(parts, column)
+		}
+
+		index.Name = table.Name + "_" + strings.Join(parts, "_") + "_idx"
+	}
+
+	if existing, _ := mc.index(relationName{Schema: name.Schema, Name: index.Name}); existing != nil {
+		if ifNotExists {
+			return nil
+		}
+
+		return fmt.Errorf("index [%s] already exists", index.Name)
+	}
+
+	table.Indexes = append(table.Indexes, index)
+	slices.SortFunc(table.Indexes, func(a, b PgIndex) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return nil
+}
+
+func (mc *migrationCatalog) alterIndex(p *ddlParser) error {
+	ifExists := p.acceptKeyword("if", "exists")
+	name, err := p.qualifiedName(mc.searchPath)
+	if err != nil {
+		return err
+	}
+
+	if !p.acceptKeyword("rename", "to") {
+		return nil
+	}
+
+	newName, err := p.identifier()
+	if err != nil {
+		return err
+	}
+
+	table, i := mc.index(name)
+	if table == nil {
+		if ifExists {
+			return nil
+		}
+
+		return fmt.Errorf("index [%s] does not exist", name)
+	}
+
+	table.Indexes[i].Name = newName
+
+	return nil
+}
+
+func (mc *migrationCatalog) dropIndexes(p *ddlParser) error {
+	p.acceptKeyword("concurrently")
+	ifExists := p.acceptKeyword("if", "exists")
+
+	for _, item := range splitList(p.rest()) {
+		name, err := (&ddlParser{tokens: item}).qualifiedName(mc.searchPath)
+		if err != nil {
+			return err
+		}
+
+		table, i := mc.index(name)
+		if table == nil {
+			if ifExists {
+				continue
+			}
+
+			return fmt.Errorf("index [%s] does not exist", name)
+		}
+
+		table.Indexes = slices.Delete(table.Indexes, i, i+1)
+	}
+
+	return nil
+}
+
+// comment applies COMMENT ON TABLE, VIEW and COLUMN statements, comments on
+// other objects are skipped
+func (mc *migrationCatalog) comment(p *ddlParser) error {
+	kind := _table
+	switch {
+	case p.acceptKeyword("table"):
+	case p.acceptKeyword("view"):
+		kind = _view
+	case p.acceptKeyword("column"):
+		kind = ""
+	default:
+		return nil
+	}
+
+	var names []string
+	for {
+		name, err := p.identifier()
+		if err != nil {
+			return err
+		}
+
+		names = append(names, name)
+		if !p.acceptSymbol(".") {
+			break
+		}
+	}
+
+	if err := p.expectKeyword("is"); err != nil {
+		return err
+	}
+
+	var text string
+	if token := p.next(); token.Kind == _stringToken {
+		text = token.Text
+	} else if !token.isKeyword("null") {
+		return fmt.Errorf("expected a string or [NULL], got [%s]", token)
+	}
+
+	var columnName string
+	if strIsEmpty(kind) {
+		if len(names) < 2 {
+			return fmt.Errorf("expected a column name, got [%s]", strings.Join(names, "."))
+		}
+
+		columnName = names[len(names)-1]
+		names = names[:len(names)-1]
+	}
+
+	name := relationName{Schema: mc.searchPath, Name: names[len(names)-1]}
+	if len(names) > 1 {
+		name.Schema = names[len(names)-2]
+	}
+
+	table, ok := mc.relations[name]
+	if !ok || (!strIsEmpty(kind) && table.Kind != kind) {
+		return fmt.Errorf("relation [%s] does not exist", name)
+	}
+
+	if strIsEmpty(columnName) {
+		table.Comment = text
+		return nil
+	}
+
+	column := table.column(columnName)
+	if column == nil {
+		return fmt.Errorf("column [%s] of [%s] does not exist", columnName, name)
+	}
+
+	column.Comment = text
+
+	return nil
+}
+
+func (mc *migrationCatalog) createType(p *ddlParser) error {
+	name, err := p.qualifiedName(mc.searchPath)
+	if err != nil {
+		return err
+	}
+
+	if !p.acceptKeyword("as", "enum") {
+		return fmt.Errorf("type [%s] is not an enum, only enum types are supported", name)
+	}
+
+	if _, ok := mc.enums[name]; ok {
+		return fmt.Errorf("type [%s] already exists", name)
+	}
+
+	values, err := p.group()
+	if err != nil {
+		return err
+	}
+
+	enum := &PgEnum{
+		Name: name.Name,
+	}
+
+	for _, item := range splitList(values) {
+		if len(item) != 1 || item[0].Kind != _stringToken {
+			return fmt.Errorf("expected an enum value, got [%s]", tokensText(item))
+		}
+
+		enum.Values = append(enum.Values, item[0].Text)
+	}
+
+	mc.enums[name] = enum
+
+	return nil
+}
+
+func (mc *migrationCatalog) alterType(p *ddlParser) error {
+	name, err := p.qualifiedName(mc.searchPath)
+	if err != nil {
+		return err
+	}
+
+	enum, ok := mc.enums[name]
+	if !ok {
+		return fmt.Errorf("type [%s] does not exist", name)
+	}
+
+	switch {
+	case p.acceptKeyword("add", "value"):
+		ifNotExists := p.acceptKeyword("if", "not", "exists")
+		value := p.next()
+		if value.Kind != _stringToken {
+			return fmt.Errorf("expected an enum value, got [%s]", value)
+		}
+
+		if slices.Contains(enum.Values, value.Text) {
+			if ifNotExists {
+				return nil
+			}
+
+			return fmt.Errorf("value [%s] of type [%s] already exists", value.Text, name)
+		}
+
+		position := len(enum.Values)
+		if before, after := p.acceptKeyword("before"), p.acceptKeyword("after"); before || after {
+			neighbor := p.next()
+			position = slices.Index(enum.Values, neighbor.Text)
+			if position < 0 {
+				return fmt.Errorf("value [%s] of type [%s] does not exist", neighbor.Text, name)
+			}
+
+			if after {
+				position++
+			}
+		}
+
+		enum.Values = slices.Insert(enum.Values, position, value.Text)
+
+	case p.acceptKeyword("rename", "value"):
+		oldValue := p.next()
+		if err := p.expectKeyword("to"); err != nil {
+			return err
+		}
+
+		newValue := p.next()
+
+		i := slices.Index(enum.Values, oldValue.Text)
+		if i < 0 {
+			return fmt.Errorf("value [%s] of type [%s] does not exist", oldValue.Text, name)
+		}
+
+		enum.Values[i] = newValue.Text
+
+	case p.acceptKeyword("rename", "to"):
+		newName, err := p.identifier()
+		if err != nil {
+			return err
+		}
+
+		delete(mc.enums, name)
+		enum.Name = newName
+		mc.enums[relationName{Schema: name.Schema, Name: newName}] = enum
+
+	case p.isKeyword("owner"):
+
+	default:
+		return fmt.Errorf("unsupported action [%s] on type [%s]", tokensText(p.rest()), name)
+	}
+
+	return nil
+}
+
+func (mc *migrationCatalog) dropTypes(p *ddlParser) error {
+	ifExists := p.acceptKeyword("if", "exists")
+
+	for _, item := range splitList(p.rest()) {
+		name, err := (&ddlParser{tokens: item}).qualifiedName(mc.searchPath)
+		if err != nil {
+			return err
+		}
+
+		if _, ok := mc.enums[name]; !ok && !ifExists {
+			return fmt.Errorf("type [%s] does not exist", name)
+		}
+
+		delete(mc.enums, name)
+	}
+
+	return nil
+}
+
+func (mc *migrationCatalog) createView(p *ddlParser, orReplace bool) error {
+	name, err := p.qualifiedName(mc.searchPath)
+	if err != nil {
+		return err
+	}
+
+	if existing, ok := mc.relations[name]; ok && (!orReplace || existing.Kind != _view) {
+		return fmt.Errorf("relation [%s] already exists", name)
+	}
+
+	var columnNames []string
+	if p.peek().isSymbol("(") {
+		columnNames, err = p.identifierList()
+		if err != nil {
+			return err
+		}
+	}
+
+	if p.acceptKeyword("with") {
+		if _, err := p.group(); err != nil {
+			return err
+		}
+	}
+
+	if err := p.expectKeyword("as"); err != nil {
+		return err
+	}
+
+	columns, err := mc.viewColumns(p, name)
+	if err != nil {
+		return err
+	}
+
+	if len(columnNames) > len(columns) {
+		return fmt.Errorf("view [%s] has more column names than columns", name)
+	}
+
+	for i, columnName := range columnNames {
+		columns[i].Name = columnName
+	}
+
+	for i, column := range columns {
+		if slices.ContainsFunc(columns[:i], func(c PgColumn) bool { return c.Name == column.Name }) {
+			return fmt.Errorf("column [%s] of view [%s] is specified more than once", column.Name, name)
+		}
+	}
+
+	mc.relations[name] = &PgTable{
+		Kind:    _view,
+		Name:    name.Name,
+		Columns: columns,
+	}
+
+	return nil
+}
+
+// viewColumns resolves the columns of a view from the select list of its
+// query, types that can not be resolved are reported and left blank
+func (mc *migrationCatalog) viewColumns(p *ddlParser, view relationName) ([]PgColumn, error) {
+	if !p.acceptKeyword("select") {
+		return nil, fmt.Errorf("view [%s] must be defined by a SELECT", view)
+	}
+
+	if p.acceptKeyword("distinct") {
+		if p.acceptKeyword("on") {
+			if _, err := p.group(); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	p.acceptKeyword("all")
+
+	selectList := p.until(append([]string{"from"}, _fromClauseEndKeywords...)...)
+
+	var sources []viewSource
+	if p.acceptKeyword("from") {
+		sources = mc.viewSources(&ddlParser{tokens: p.until(_fromClauseEndKeywords...)})
+	}
+
+	var columns []PgColumn
+	for _, item := range splitList(selectList) {
+		columns = append(columns, mc.viewColumn(item, sources, view)...)
+	}
+
+	return columns, nil
+}
+
+func (p *ddlParser) alias() string {
+	if p.acceptKeyword("as") {
+		alias, _ := p.identifier()
+		return alias
+	}
+
+	token := p.peek()
+	if token.Kind == _identifierToken && (token.Quoted || !slices.Contains(_joinKeywords, token.Text)) {
+		p.next()
+		return token.Text
+	}
+
+	return ""
+}
+
+func (mc *migrationCatalog) viewSources(p *ddlParser) []viewSource {
+	var sources []viewSource
+	expectSource := true
+
+	for !p.done() {
+		token := p.peek()
+		switch {
+		case expectSource && token.isKeyword("lateral"), expectSource && token.isKeyword("only"):
+			p.next()
+
+		case expectSource && token.isSymbol("("):
+			p.group()
+			sources = append(sources, viewSource{Alias: p.alias()})
+			expectSource = false
+
+		case expectSource && token.Kind == _identifierToken:
+			name, _ := p.qualifiedName(mc.searchPath)
+
+			var table *PgTable
+			if p.peek().isSymbol("(") {
+				p.group()
+			} else if table = mc.relations[name]; table == nil {
+				mc.warn("view references the unknown relation [%s]", name)
+			}
+
+			alias := p.alias()
+			if strIsEmpty(alias) {
+				alias = name.Name
+			}
+
+			if p.peek().isSymbol("(") {
+				p.group()
+			}
+
+			sources = append(sources, viewSource{Alias: alias, Table: table})
+			expectSource = false
+
+		case token.isKeyword("join"), token.isSymbol(","):
+			p.next()
+			expectSource = true
+
+		default:
+			p.skipUnit()
+		}
+	}
+
+	return sources
+}
+
+func isColumnReference(expression []sqlToken) bool {
+	if len(expression)%2 == 0 {
+		return false
+	}
+
+	for i, token := range expression {
+		if i%2 == 0 && token.Kind != _identifierToken {
+			return false
+		}
+
+		if i%2 == 1 && !token.isSymbol(".") {
+			return false
+		}
+	}
+
+	return true
+}
+
+// itemAlias splits a select list item into its expression and alias
+func itemAlias(item []sqlToken) ([]sqlToken, string) {
+	n := len(item)
+	if n >= 2 && item[n-2].isKeyword("as") {
+		return item[:n-2], item[n-1].Text
+	}
+
+	if n < 2 || item[n-1].Kind != _identifierToken {
+		return item, ""
+	}
+
+	last, previous := item[n-1], item[n-2]
+	if !last.Quoted && slices.Contains([]string{"end", "null", "true", "false"}, last.Text) {
+		return item, ""
+	}
+
+	switch {
+	case previous.Kind == _stringToken, previous.Kind == _numberToken, previous.isSymbol(")"):
+		return item[:n-1], last.Text
+	case previous.Kind == _identifierToken && isColumnReference(item[:n-1]):
+		return item[:n-1], last.Text
+	case previous.Kind == _identifierToken && n >= 3 && item[n-3].isSymbol("::"):
+		return item[:n-1], last.Text
+	}
+
+	return item, ""
+}
+
+func (mc *migrationCatalog) viewColumn(item []sqlToken, sources []viewSource, view relationName) []PgColumn {
+	expression, alias := itemAlias(item)
+
+	if len(expression) == 1 && expression[0].isSymbol("*") {
+		return mc.viewStarColumns(sources, "")
+	}
+
+	if len(expression) == 3 && expression[0].Kind == _identifierToken && expression[1].isSymbol(".") && expression[2].isSymbol("*") {
+		return mc.viewStarColumns(sources, expression[0].Text)
+	}
+
+	name, sqlDataType := mc.viewExpression(expression, sources)
+	if !strIsEmpty(alias) {
+		name = alias
+	}
+
+	if strIsEmpty(sqlDataType) {
+		mc.warn("type of column [%s] of view [%s] could not be resolved", name, view)
+	}
+
+	sqlDataType = strings.ToLower(sqlDataType)
+
+	return []PgColumn{{
+		Name:        name,
+		SqlDataType: sqlDataType,
+		GoDataType:  goDataType(sqlDataType),
+	}}
+}
+
+func (mc *migrationCatalog) viewStarColumns(sources []viewSource, alias string) []PgColumn {
+	var columns []PgColumn
+	for _, source := range sources {
+		if source.Table == nil || (!strIsEmpty(alias) && source.Alias != alias) {
+			continue
+		}
+
+		for _, column := range source.Table.Columns {
+			sqlDataType := strings.ToLower(column.SqlDataType)
+			columns = append(columns, PgColumn{
+				Name:        column.Name,
+				SqlDataType: sqlDataType,
+				GoDataType:  goDataType(sqlDataType),
+			})
+		}
+	}
+
+	return columns
+}
+
+// viewExpression returns the column name Postgres gives to the expression and
+// its type, which is blank when it can not be resolved
+func (mc *migrationCatalog) viewExpression(expression []sqlToken, sources []viewSource) (string, string) {
+	n := len(expression)
+	if n == 0 {
+		return "?column?", ""
+	}
+
+	for i := n - 1; i > 0; i-- {
+		if !expression[i].isSymbol("::") {
+			continue
+		}
+
+		dataType, err := (&ddlParser{tokens: expression[i+1:]}).dataType()
+		if err != nil {
+			return "?column?", ""
+		}
+
+		sqlDataType := dataType.Name
+
+		name, _ := mc.viewExpression(expression[:i], sources)
+		if name == "?column?" {
+			name = strings.ToLower(sqlDataType)
+		}
+
+		return name, sqlDataType
+	}
+
+	if isColumnReference(expression) {
+		columnName := expression[n-1].Text
+
+		var qualifier string
+		if n >= 3 {
+			qualifier = expression[n-3].Text
+		}
+
+		for _, source := range sources {
+			if source.Table == nil || (!strIsEmpty(qualifier) && source.Alias != qualifier) {
+				continue
+			}
+
+			if column := source.Table.column(columnName); column != nil {
+				return column.Name, column.SqlDataType
+			}
+		}
+
+		return columnName, ""
+	}
+
+	if n == 1 {
+		switch token := expression[0]; {
+		case token.Kind == _stringToken:
+			return "?column?", "TEXT"
+		case token.Kind == _numberToken && strings.ContainsAny(token.Text, ".eE"):
+			return "?column?", "NUMERIC"
+		case token.Kind == _numberToken:
+			return "?column?", "INT4"
+		case token.isKeyword("true"), token.isKeyword("false"):
+			return "bool", "BOOL"
+		}
+	}
+
+	if expression[0].Kind == _identifierToken && n >= 2 && expression[1].isSymbol("(") {
+		function := expression[0].Text
+		if function == "cast" {
+			inner := expression[2 : n-1]
+			for i, token := range inner {
+				if token.isKeyword("as") {
+					name, _ := mc.viewExpression(inner[:i], sources)
+					dataType, _ := (&ddlParser{tokens: inner[i+1:]}).dataType()
+					sqlDataType := dataType.Name
+					if name == "?column?" {
+						name = strings.ToLower(sqlDataType)
+					}
+
+					return name, sqlDataType
+				}
+			}
+		}
+
+		return function, _viewFunctionTypes[function]
+	}
+
+	if expression[0].isKeyword("case") {
+		return "case", ""
+	}
+
+	return "?column?", ""
+}
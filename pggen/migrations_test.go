@@ -0,0 +1,417 @@
+package pggen
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTokenizeSQL(t *testing.T) {
+	tests := []struct {
+		name     string
+		sql      string
+		expected []sqlToken
+	}{
+		{
+			name: "lowercases unquoted identifiers",
+			sql:  "CREATE TABLE Projects",
+			expected: []sqlToken{
+				{Kind: _identifierToken, Text: "create", Line: 1},
+				{Kind: _identifierToken, Text: "table", Line: 1},
+				{Kind: _identifierToken, Text: "projects", Line: 1},
+			},
+		},
+		{
+			name: "keeps the case of quoted identifiers",
+			sql:  `"My ""Table"""`,
+			expected: []sqlToken{
+				{Kind: _identifierToken, Text: `My "Table"`, Quoted: true, Line: 1},
+			},
+		},
+		{
+			name: "unescapes doubled quotes in strings",
+			sql:  `'it''s'`,
+			expected: []sqlToken{
+				{Kind: _stringToken, Text: "it's", Line: 1},
+			},
+		},
+		{
+			name: "unescapes backslashes in escape strings",
+			sql:  `E'it\'s'`,
+			expected: []sqlToken{
+				{Kind: _stringToken, Text: "it's", Line: 1},
+			},
+		},
+		{
+			name: "reads dollar quoted strings as strings",
+			sql:  "$body$ SELECT 'a'; $$ $body$ $$x$$",
+			expected: []sqlToken{
+				{Kind: _stringToken, Text: " SELECT 'a'; $$ ", Line: 1},
+				{Kind: _stringToken, Text: "x", Line: 1},
+			},
+		},
+		{
+			name: "reads positional parameters as symbols",
+			sql:  "id = $12",
+			expected: []sqlToken{
+				{Kind: _identifierToken, Text: "id", Line: 1},
+				{Kind: _symbolToken, Text: "=", Line: 1},
+				{Kind: _symbolToken, Text: "$12", Line: 1},
+			},
+		},
+		{
+			name: "groups operators and numbers",
+			sql:  "total::numeric >= 1.5",
+			expected: []sqlToken{
+				{Kind: _identifierToken, Text: "total", Line: 1},
+				{Kind: _symbolToken, Text: "::", Line: 1},
+				{Kind: _identifierToken, Text: "numeric", Line: 1},
+				{Kind: _symbolToken, Text: ">=", Line: 1},
+				{Kind: _numberToken, Text: "1.5", Line: 1},
+			},
+		},
+		{
+			name: "skips comments and counts their lines",
+			sql:  "a -- comment ;\n/* multi\nline */ b\nc",
+			expected: []sqlToken{
+				{Kind: _identifierToken, Text: "a", Line: 1},
+				{Kind: _identifierToken, Text: "b", Line: 3},
+				{Kind: _identifierToken, Text: "c", Line: 4},
+			},
+		},
+		{
+			name: "counts the lines of multi line strings",
+			sql:  "'a\nb' c",
+			expected: []sqlToken{
+				{Kind: _stringToken, Text: "a\nb", Line: 1},
+				{Kind: _identifierToken, Text: "c", Line: 2},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tokens, err := tokenizeSQL(test.sql)
+			if err != nil {
+				t.Fatalf("expected no error, got [%s]", err.Error())
+			}
+
+			if !reflect.DeepEqual(tokens, test.expected) {
+				t.Errorf("expected tokens %+v, got %+v", test.expected, tokens)
+			}
+		})
+	}
+}
+
+func TestTokenizeSQLErrors(t *testing.T) {
+	tests := []struct {
+		sql      string
+		expected string
+	}{
+		{sql: "a\n/* comment", expected: "unterminated comment at line [2]"},
+		{sql: "'string", expected: "unterminated string at line [1]"},
+		{sql: `E'it\'`, expected: "unterminated string at line [1]"},
+		{sql: "\n\n\"name", expected: "unterminated quoted identifier at line [3]"},
+		{sql: "$tag$ body $other$", expected: "unterminated dollar quoted string at line [1]"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.expected, func(t *testing.T) {
+			_, err := tokenizeSQL(test.sql)
+			if err == nil || err.Error() != test.expected {
+				t.Errorf("expected error [%s], got [%v]", test.expected, err)
+			}
+		})
+	}
+}
+
+func TestSplitStatements(t *testing.T) {
+	tokens, err := tokenizeSQL("CREATE TABLE a (id INT);\n\n;\nINSERT INTO a\nVALUES (';')")
+	if err != nil {
+		t.Fatalf("expected no error, got [%s]", err.Error())
+	}
+
+	statements := splitStatements("001.sql", tokens)
+	if len(statements) != 2 {
+		t.Fatalf("expected 2 statements, got %d", len(statements))
+	}
+
+	for i, expected := range []string{"001.sql:1", "001.sql:4"} {
+		if location := statements[i].location(); location != expected {
+			t.Errorf("expected statement %d at [%s], got [%s]", i, expected, location)
+		}
+	}
+}
+
+// applyMigration applies the SQL to a new catalog, returning it with the
+// warnings it logged
+func applyMigration(t *testing.T, path, sql string) (*migrationCatalog, string) {
+	t.Helper()
+
+	if !strIsEmpty(sql) {
+		path = filepath.Join(t.TempDir(), "001_migration.sql")
+		if err := os.WriteFile(path, []byte(sql), 0o644); err != nil {
+			t.Fatalf("failed to write migration, got error [%s]", err.Error())
+		}
+	}
+
+	var warnings bytes.Buffer
+	log.SetOutput(&warnings)
+	defer log.SetOutput(os.Stderr)
+
+	catalog := newMigrationCatalog()
+	if err := catalog.applyFile(path); err != nil {
+		t.Fatalf("expected no error, got [%s]", err.Error())
+	}
+
+	return catalog, warnings.String()
+}
+
+func TestMigrationCatalogExample(t *testing.T) {
+	catalog, warnings := applyMigration(t, "../example/migrations/setup.sql", "")
+	if !strIsEmpty(warnings) {
+		t.Errorf("expected no warnings, got [%s]", warnings)
+	}
+
+	schema := catalog.schema("public", ConfigSchema{IncludeViews: true})
+
+	expectedEnums := []PgEnum{{Name: "project_tier", Values: []string{"free", "premium", "ultimate"}}}
+	if !reflect.DeepEqual(schema.Enums, expectedEnums) {
+		t.Errorf("expected enums %+v, got %+v", expectedEnums, schema.Enums)
+	}
+
+	expectedTables := []PgTable{
+		{
+			Kind: _table,
+			Name: "projects",
+			Columns: []PgColumn{
+				{Name: "id", SqlDataType: "UUID", GoDataType: "uuid.UUID", Default: "gen_random_uuid()", IsPrimaryKey: true},
+				{Name: "created_at", SqlDataType: "TIMESTAMP", GoDataType: "time.Time", Nullable: true, Default: "now()"},
+				{Name: "name", SqlDataType: "VARCHAR", GoDataType: "string", MaxLength: 255},
+				{Name: "description", SqlDataType: "VARCHAR", GoDataType: "string", MaxLength: 500, Nullable: true},
+				{Name: "tier", SqlDataType: "PROJECT_TIER", GoDataType: "any", Default: "'free'"},
+			},
+			Constraints: []PgConstraint{{Name: "projects_pkey", Type: "p", Columns: []string{"id"}}},
+		},
+		{
+			Kind: _view,
+			Name: "v_free_projects",
+			Columns: []PgColumn{
+				{Name: "id", SqlDataType: "uuid", GoDataType: "uuid.UUID"},
+				{Name: "name", SqlDataType: "varchar", GoDataType: "string"},
+				{Name: "desc", SqlDataType: "varchar", GoDataType: "string"},
+			},
+		},
+	}
+
+	if len(schema.Tables) != len(expectedTables) {
+		t.Fatalf("expected %d tables, got %+v", len(expectedTables), schema.Tables)
+	}
+
+	for i, expected := range expectedTables {
+		table := schema.Tables[i]
+		if table.Kind != expected.Kind || table.Name != expected.Name {
+			t.Errorf("expected %s [%s], got %s [%s]", expected.Kind, expected.Name, table.Kind, table.Name)
+		}
+
+		if !reflect.DeepEqual(table.Columns, expected.Columns) {
+			t.Errorf("expected columns %+v of [%s], got %+v", expected.Columns, expected.Name, table.Columns)
+		}
+
+		if len(table.Constraints) != len(expected.Constraints) {
+			t.Errorf("expected constraints %+v of [%s], got %+v", expected.Constraints, expected.Name, table.Constraints)
+			continue
+		}
+
+		for j, constraint := range table.Constraints {
+			if constraint.Name != expected.Constraints[j].Name || constraint.Type != expected.Constraints[j].Type ||
+				!reflect.DeepEqual(constraint.Columns, expected.Constraints[j].Columns) {
+				t.Errorf("expected constraint %+v of [%s], got %+v", expected.Constraints[j], expected.Name, constraint)
+			}
+		}
+	}
+
+	if tables := catalog.schema("public", ConfigSchema{}).Tables; len(tables) != 1 {
+		t.Errorf("expected views to be left out when they are not included, got %d tables", len(tables))
+	}
+}
+
+func TestMigrationCatalog(t *testing.T) {
+	tests := []struct {
+		name       string
+		sql        string
+		schemaName string
+		table      string
+		expected   []PgColumn
+	}{
+		{
+			name:       "serial columns default to their sequence",
+			sql:        "CREATE TABLE items (id BIGSERIAL PRIMARY KEY, code SERIAL);",
+			schemaName: "public",
+			table:      "items",
+			expected: []PgColumn{
+				{Name: "id", SqlDataType: "INT8", GoDataType: "any", Default: "nextval('items_id_seq'::regclass)", IsPrimaryKey: true},
+				{Name: "code", SqlDataType: "INT4", GoDataType: "int64", Default: "nextval('items_code_seq'::regclass)"},
+			},
+		},
+		{
+			name:       "type modifiers set the length, precision and scale",
+			sql:        "CREATE TABLE items (code CHAR(3), name CHARACTER VARYING(40) NOT NULL, price DECIMAL(10, 2), note TEXT);",
+			schemaName: "public",
+			table:      "items",
+			expected: []PgColumn{
+				{Name: "code", SqlDataType: "BPCHAR", GoDataType: "any", MaxLength: 3, Nullable: true},
+				{Name: "name", SqlDataType: "VARCHAR", GoDataType: "string", MaxLength: 40},
+				{Name: "price", SqlDataType: "NUMERIC", GoDataType: "any", Precision: 10, Scale: 2, Nullable: true},
+				{Name: "note", SqlDataType: "TEXT", GoDataType: "string", Nullable: true},
+			},
+		},
+		{
+			name:       "quoted names keep their case",
+			sql:        `CREATE TABLE "Items" ("ID" INT NOT NULL); COMMENT ON COLUMN "Items"."ID" IS 'The ''item'' id';`,
+			schemaName: "public",
+			table:      "Items",
+			expected: []PgColumn{
+				{Name: "ID", SqlDataType: "INT4", GoDataType: "int64", Comment: "The 'item' id"},
+			},
+		},
+		{
+			name: "search path sets the schema of unqualified names",
+			sql: `CREATE SCHEMA billing;
+				SET search_path TO billing, public;
+				CREATE TABLE invoices (id INT);`,
+			schemaName: "billing",
+			table:      "invoices",
+			expected: []PgColumn{
+				{Name: "id", SqlDataType: "INT4", GoDataType: "int64", Nullable: true},
+			},
+		},
+		{
+			name: "alter table changes the columns",
+			sql: `CREATE TABLE items (id INT, qty INT, price NUMERIC(8, 3));
+				ALTER TABLE items ADD COLUMN name VARCHAR(20) DEFAULT 'none';
+				ALTER TABLE items ADD COLUMN IF NOT EXISTS name TEXT;
+				ALTER TABLE items RENAME COLUMN qty TO quantity;
+				ALTER TABLE items DROP COLUMN price;
+				ALTER TABLE items ALTER COLUMN quantity TYPE BIGINT;
+				ALTER TABLE items ALTER COLUMN quantity SET NOT NULL;
+				ALTER TABLE items ALTER COLUMN quantity SET DEFAULT 1;
+				ALTER TABLE items ALTER COLUMN name DROP DEFAULT;
+				ALTER TABLE items ALTER COLUMN id SET DATA TYPE TEXT;`,
+			schemaName: "public",
+			table:      "items",
+			expected: []PgColumn{
+				{Name: "id", SqlDataType: "TEXT", GoDataType: "string", Nullable: true},
+				{Name: "quantity", SqlDataType: "INT8", GoDataType: "any", Default: "1"},
+				{Name: "name", SqlDataType: "VARCHAR", GoDataType: "string", MaxLength: 20, Nullable: true},
+			},
+		},
+		{
+			name: "renamed tables keep their columns",
+			sql: `CREATE TABLE public.items (id INT);
+				ALTER TABLE items RENAME TO products;`,
+			schemaName: "public",
+			table:      "products",
+			expected: []PgColumn{
+				{Name: "id", SqlDataType: "INT4", GoDataType: "int64", Nullable: true},
+			},
+		},
+		{
+			name: "function bodies and data statements are ignored",
+			sql: `CREATE TABLE items (id INT);
+				CREATE FUNCTION touch() RETURNS trigger AS $$ BEGIN DROP TABLE items; END; $$ LANGUAGE plpgsql;
+				INSERT INTO items (id) VALUES (1);`,
+			schemaName: "public",
+			table:      "items",
+			expected: []PgColumn{
+				{Name: "id", SqlDataType: "INT4", GoDataType: "int64", Nullable: true},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			catalog, _ := applyMigration(t, "", test.sql)
+
+			schema := catalog.schema(test.schemaName, ConfigSchema{})
+			if len(schema.Tables) != 1 || schema.Tables[0].Name != test.table {
+				t.Fatalf("expected only table [%s], got %+v", test.table, schema.Tables)
+			}
+
+			if !reflect.DeepEqual(schema.Tables[0].Columns, test.expected) {
+				t.Errorf("expected columns %+v, got %+v", test.expected, schema.Tables[0].Columns)
+			}
+		})
+	}
+}
+
+func TestMigrationCatalogConstraints(t *testing.T) {
+	catalog, _ := applyMigration(t, "", `CREATE TABLE owners (id INT PRIMARY KEY);
+		CREATE TABLE items (
+			id INT,
+			owner_id INT REFERENCES owners,
+			total NUMERIC CHECK (total >= 0),
+			CONSTRAINT items_id_pk PRIMARY KEY (id)
+		);
+		ALTER TABLE items ADD CONSTRAINT items_total_unique UNIQUE (total);
+		ALTER TABLE items RENAME CONSTRAINT items_id_pk TO items_pkey;`)
+
+	schema := catalog.schema("public", ConfigSchema{})
+	expected := []PgConstraint{
+		{Name: "items_owner_id_fkey", Type: "f", Columns: []string{"owner_id"}, RefSchema: "public", RefTable: "owners", RefColumns: []string{"id"}},
+		{Name: "items_pkey", Type: "p", Columns: []string{"id"}},
+		{Name: "items_total_check", Type: "c", Columns: []string{"total"}, Check: "total >= 0"},
+		{Name: "items_total_unique", Type: "u", Columns: []string{"total"}},
+	}
+
+	if len(schema.Tables) != 2 || !reflect.DeepEqual(schema.Tables[0].Constraints, expected) {
+		t.Errorf("expected constraints %+v, got %+v", expected, schema.Tables)
+	}
+}
+
+func TestMigrationCatalogEnums(t *testing.T) {
+	catalog, _ := applyMigration(t, "", `CREATE TYPE status AS ENUM ('open', 'closed');
+		ALTER TYPE status ADD VALUE 'draft' BEFORE 'open';
+		ALTER TYPE status ADD VALUE IF NOT EXISTS 'closed';
+		ALTER TYPE status RENAME VALUE 'closed' TO 'done';
+		CREATE TYPE kind AS ENUM ();
+		ALTER TYPE kind RENAME TO category;`)
+
+	expected := []PgEnum{
+		{Name: "category"},
+		{Name: "status", Values: []string{"draft", "open", "done"}},
+	}
+
+	if enums := catalog.schema("public", ConfigSchema{}).Enums; !reflect.DeepEqual(enums, expected) {
+		t.Errorf("expected enums %+v, got %+v", expected, enums)
+	}
+}
+
+func TestMigrationCatalogWarnings(t *testing.T) {
+	_, warnings := applyMigration(t, "", `CREATE TABLE items (id INT);
+
+		CREATE FUNCTION touch() RETURNS int AS $$
+			SELECT 1;
+		$$ LANGUAGE sql;
+		ALTER TABLE missing ADD COLUMN id INT;`)
+
+	expected := []string{
+		"001_migration.sql:3] unsupported statement [CREATE FUNCTION] was skipped",
+		"001_migration.sql:6] statement [ALTER TABLE] was skipped, got error [table [public.missing] does not exist]",
+	}
+
+	lines := strings.Split(strings.TrimSpace(warnings), "\n")
+	if len(lines) != len(expected) {
+		t.Fatalf("expected %d warnings, got [%s]", len(expected), warnings)
+	}
+
+	for i := range expected {
+		if !strings.HasSuffix(lines[i], expected[i]) {
+			t.Errorf("expected warning ending with [%s], got [%s]", expected[i], lines[i])
+		}
+	}
+}
@@ -0,0 +1,393 @@
+package pggen
+
+import (
+	"fmt"
+	"go/token"
+	"slices"
+	"strings"
+
+	"github.com/iancoleman/strcase"
+)
+
+// _goCommonIdentifiers are the exported identifiers declared by the common
+// file, which no entity can be named after
+var _goCommonIdentifiers = []string{
+	"Constraint", "DatabaseError", "DeleteOptions", "Direction", "ErrCheckViolation",
+	"ErrForeignKeyViolation", "ErrNotFound", "ErrNotNullViolation", "ErrUniqueViolation", "Filter",
+	"NewDirection", "NewFilter", "OrderBy", "SelectOptions", "SelectResult", "Transaction",
+	"UpdateOptions", "Where",
+}
+
+// _goEntityMethods are the methods of the table and view entities, which no
+// field can be named after
+var _goEntityMethods = map[string][]string{
+	_table: {"Count", "Select", "Insert", "InsertTx", "Update", "UpdateTx", "Delete", "DeleteTx"},
+	_view:  {"Count", "Select"},
+}
+
+func goCommonIdentifiers(config *ConfigSchemaGO) []string {
+	if config.EmitValidation {
+		return append(slices.Clone(_goCommonIdentifiers), "FieldError", "ValidationError")
+	}
+
+	return _goCommonIdentifiers
+}
+
+func goEntityMethods(config *ConfigSchemaGO, kind string) []string {
+	if config.EmitValidation && kind == _table {
+		return append(slices.Clone(_goEntityMethods[kind]), "Validate")
+	}
+
+	return _goEntityMethods[kind]
+}
+
+// _irregularPlurals are the plural words whose singular does not follow the
+// suffix rules of singular
+var _irregularPlurals = map[string]string{
+	"people":     "person",
+	"children":   "child",
+	"men":        "man",
+	"women":      "woman",
+	"mice":       "mouse",
+	"geese":      "goose",
+	"feet":       "foot",
+	"teeth":      "tooth",
+	"criteria":   "criterion",
+	"indices":    "index",
+	"matrices":   "matrix",
+	"vertices":   "vertex",
+	"appendices": "appendix",
+	"crises":     "crisis",
+	"theses":     "thesis",
+	"diagnoses":  "diagnosis",
+	"heroes":     "hero",
+	"potatoes":   "potato",
+	"tomatoes":   "tomato",
+	"echoes":     "echo",
+	"lives":      "life",
+	"wives":      "wife",
+	"knives":     "knife",
+	"leaves":     "leaf",
+	"halves":     "half",
+	"shelves":    "shelf",
+	"wolves":     "wolf",
+	"thieves":    "thief",
+	"abuses":     "abuse",
+	"excuses":    "excuse",
+	"uses":       "use",
+}
+
+// _uncountableWords are the words that are the same in the singular and the
+// plural, or that have no singular
+var _uncountableWords = []string{
+	"data", "metadata", "media", "series", "species", "news", "information", "equipment", "sheep", "fish",
+}
+
+// _iePlurals are the plural words ending in ies whose singular ends in ie
+var _iePlurals = []string{
+	"movies", "cookies", "zombies", "calories", "selfies", "rookies", "hippies", "brownies", "freebies",
+	"goalies", "newbies", "sorties", "smoothies", "genies", "prairies", "aunties", "birdies", "budgies",
+}
+
+// singular returns the singular of an English plural word, words that are
+// not plural, or whose singular is not known, are returned as they are
+func singular(word string) string {
+	if single, ok := _irregularPlurals[word]; ok {
+		return single
+	}
+
+	if slices.Contains(_uncountableWords, word) {
+		return word
+	}
+
+	if slices.Contains(_iePlurals, word) {
+		return strings.TrimSuffix(word, "s")
+	}
+
+	switch {
+	case strings.HasSuffix(word, "ies") && len(word) > 4:
+		return strings.TrimSuffix(word, "ies") + "y"
+	case strings.HasSuffix(word, "yses"):
+		return strings.TrimSuffix(word, "es") + "is"
+	case strings.HasSuffix(word, "sses"), strings.HasSuffix(word, "shes"), strings.HasSuffix(word, "ches"),
+		strings.HasSuffix(word, "xes"), strings.HasSuffix(word, "zzes"):
+		return strings.TrimSuffix(word, "es")
+	case strings.HasSuffix(word, "uses") && len(word) > 4:
+		// Words such as statuses and bonuses end in us, words such as houses
+		// and causes end in use
+		if strings.ContainsRune("aeiou", rune(word[len(word)-5])) {
+			return strings.TrimSuffix(word, "s")
+		}
+
+		return strings.TrimSuffix(word, "es")
+	case strings.HasSuffix(word, "ss"), strings.HasSuffix(word, "us"), strings.HasSuffix(word, "is"):
+		return word
+	case strings.HasSuffix(word, "s") && len(word) > 1:
+		return strings.TrimSuffix(word, "s")
+	default:
+		return word
+	}
+}
+
+// goIdentifier returns the camel case Go name of a database name, with the
+// initialisms fully upper cased (ex: owner_id as OwnerID)
+func (cn *ConfigNaming) goIdentifier(name string) string {
+	if len(cn.Initialisms) == 0 {
+		return strcase.ToCamel(name)
+	}
+
+	var sb strings.Builder
+	for word := range strings.SplitSeq(strcase.ToSnake(name), "_") {
+		if slices.ContainsFunc(cn.Initialisms, func(initialism string) bool {
+			return strings.EqualFold(initialism, word)
+		}) {
+			sb.WriteString(strings.ToUpper(word))
+			continue
+		}
+
+		sb.WriteString(strcase.ToCamel(word))
+	}
+
+	return sb.String()
+}
+
+// entityName returns the Go name of a table or view, which is the renamed
+// one or the name without its prefix, singular when configured
+func (cn *ConfigNaming) entityName(tableName string) string {
+	return cn.tableName(tableName, cn.Singular)
+}
+
+// collectionName returns the Go name of the rows of a table, used by the
+// one-to-many loaders, which is the entity name without the singular rule
+func (cn *ConfigNaming) collectionName(tableName string) string {
+	return cn.tableName(tableName, false)
+}
+
+func (cn *ConfigNaming) tableName(tableName string, singularize bool) string {
+	if goName, ok := cn.Tables[tableName]; ok {
+		return goName
+	}
+
+	name := tableName
+	for _, prefix := range cn.StripPrefixes {
+		if stripped, ok := strings.CutPrefix(name, prefix); ok && !strIsEmpty(stripped) {
+			name = stripped
+			break
+		}
+	}
+
+	if singularize {
+		words := strings.Split(strcase.ToSnake(name), "_")
+		words[len(words)-1] = singular(words[len(words)-1])
+		name = strings.Join(words, "_")
+	}
+
+	return cn.goIdentifier(name)
+}
+
+func (cn *ConfigNaming) fieldName(tableName, columnName string) string {
+	if goName, ok := cn.Columns[tableName][columnName]; ok {
+		return goName
+	}
+
+	return cn.goIdentifier(columnName)
+}
+
+// nameColumns gives the Go names to the columns of a query or function row,
+// which are the field names of the entity when the row is scanned into one
+func (cn *ConfigNaming) nameColumns(columns []PgColumn, entity *PgTable) {
+	for i := range columns {
+		if entity != nil {
+			if column := entity.column(columns[i].Name); column != nil {
+				columns[i].goField = column.goName()
+				continue
+			}
+		}
+
+		columns[i].goField = cn.goIdentifier(columns[i].Name)
+	}
+}
+
+// applyNaming gives the Go names to the tables and columns of the model,
+// failing when two of them share a name or when a name is not usable in Go
+func applyNaming(model *PgSchema, schema ConfigSchema) error {
+	naming := &schema.Naming
+	entities := make(map[string]string, len(model.Tables))
+
+	for i := range model.Tables {
+		table := &model.Tables[i]
+		table.goEntity = naming.entityName(table.Name)
+		table.goCollection = naming.collectionName(table.Name)
+		for j := range table.Constraints {
+			constraint := &table.Constraints[j]
+			constraint.goConstant = table.goEntity + naming.goIdentifier(constraint.shortName(table)) + "Constraint"
+		}
+
+		if schema.ShouldIgnore(table.Name) {
+			continue
+		}
+
+		if !token.IsIdentifier(table.goEntity) {
+			return fmt.Errorf("%s [%s] has the Go name [%s], which is not a valid identifier, rename it in $.schemas.%s.naming.tables", table.Kind, table.Name, table.goEntity, model.Name)
+		}
+
+		if other, ok := entities[table.goEntity]; ok {
+			return fmt.Errorf("%s [%s] and %s have the same Go name [%s], rename one of them in $.schemas.%s.naming.tables", table.Kind, table.Name, other, table.goEntity, model.Name)
+		}
+
+		entities[table.goEntity] = table.Kind + " [" + table.Name + "]"
+
+		fields := make(map[string]string, len(table.Columns))
+		for j := range table.Columns {
+			column := &table.Columns[j]
+			column.goField = naming.fieldName(table.Name, column.Name)
+
+			if !token.IsIdentifier(column.goField) {
+				return fmt.Errorf("column [%s] of %s [%s] has the Go name [%s], which is not a valid identifier, rename it in $.schemas.%s.naming.columns", column.Name, table.Kind, table.Name, column.goField, model.Name)
+			}
+
+			if other, ok := fields[column.goField]; ok {
+				return fmt.Errorf("columns [%s] and [%s] of %s [%s] have the same Go name [%s], rename one of them in $.schemas.%s.naming.columns", other, column.Name, table.Kind, table.Name, column.goField, model.Name)
+			}
+
+			fields[column.goField] = column.Name
+		}
+	}
+
+	return nil
+}
+
+// goNames holds the Go names declared in a scope, which is the package or
+// the fields and methods of a struct, with what declared each of them
+type goNames struct {
+	schema string
+	scope  string
+	names  map[string]string
+}
+
+func newGoNames(schemaName, scope string) *goNames {
+	return &goNames{
+		schema: schemaName,
+		scope:  scope,
+		names:  make(map[string]string),
+	}
+}
+
+// declare adds the name to the scope, failing when it was already declared
+func (gn *goNames) declare(name, declaredBy string) error {
+	if other, ok := gn.names[name]; ok {
+		return fmt.Errorf("%s and %s have the same Go name [%s] in %s, rename one of them, tables and columns are renamed in $.schemas.%s.naming", other, declaredBy, name, gn.scope, gn.schema)
+	}
+
+	gn.names[name] = declaredBy
+
+	return nil
+}
+
+// declareRowFields declares the fields of a row struct in a scope of its own
+func declareRowFields(schemaName, rowType string, columns []PgColumn) error {
+	fields := newGoNames(schemaName, "struct ["+rowType+"]")
+	for i := range columns {
+		if err := fields.declare(columns[i].goName(), "column ["+columns[i].Name+"]"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// declareGoNames returns the package names declared by the common file and
+// by the generated tables and views, failing when two package names, or two
+// fields and methods of a struct, are the same. Functions and queries declare
+// their names when they are generated
+func declareGoNames(model *PgSchema, schema ConfigSchema) (*goNames, error) {
+	names := newGoNames(model.Name, "package ["+schema.GO.Package+"]")
+	for _, identifier := range goCommonIdentifiers(schema.GO) {
+		if err := names.declare(identifier, "the generated common code"); err != nil {
+			return nil, err
+		}
+	}
+
+	for i := range model.Tables {
+		table := &model.Tables[i]
+		if schema.ShouldIgnore(table.Name) {
+			continue
+		}
+
+		if err := table.declareGoNames(names, schema.GO); err != nil {
+			return nil, err
+		}
+	}
+
+	return names, nil
+}
+
+// declareGoNames declares the entity of the table with its constraints and
+// join structs in the package, and its fields and methods in the entity
+func (t *PgTable) declareGoNames(names *goNames, config *ConfigSchemaGO) error {
+	source := t.Kind + " [" + t.Name + "]"
+	if err := names.declare(t.entityName(), source); err != nil {
+		return err
+	}
+
+	for i := range t.Constraints {
+		constraint := &t.Constraints[i]
+		if err := names.declare(constraint.goName(t), "constant of constraint ["+constraint.Name+"] of "+source); err != nil {
+			return err
+		}
+	}
+
+	members := newGoNames(names.schema, "struct ["+t.entityName()+"]")
+	for i := range t.Columns {
+		if err := members.declare(t.Columns[i].goName(), "column ["+t.Columns[i].Name+"] of "+source); err != nil {
+			return err
+		}
+	}
+
+	for _, method := range goEntityMethods(config, t.Kind) {
+		if err := members.declare(method, "method ["+method+"]"); err != nil {
+			return err
+		}
+	}
+
+	for _, relation := range t.references {
+		if err := members.declare("Load"+relation.belongsToName(), "many-to-one loader of foreign key ["+relation.Constraint.Name+"]"); err != nil {
+			return err
+		}
+	}
+
+	for _, relation := range t.referencedBy {
+		source := "one-to-many loader of foreign key [" + relation.Constraint.Name + "]"
+		if err := members.declare("Load"+relation.hasManyName(), source); err != nil {
+			return err
+		}
+
+		if err := names.declare("Load"+relation.hasManyName()+"For"+t.entityName(), "batched "+source); err != nil {
+			return err
+		}
+	}
+
+	for _, join := range t.joins() {
+		source := "join of " + source + " with [" + join.name() + "]"
+		if err := members.declare("SelectWith"+join.name(), source); err != nil {
+			return err
+		}
+
+		if err := names.declare(join.entityName(), source); err != nil {
+			return err
+		}
+
+		fields := newGoNames(names.schema, "struct ["+join.entityName()+"]")
+		if err := fields.declare(t.entityName(), "the embedded "+t.Kind+" ["+t.Name+"]"); err != nil {
+			return err
+		}
+
+		for _, relation := range join.Relations {
+			if err := fields.declare(relation.belongsToName(), "the joined table of foreign key ["+relation.Constraint.Name+"]"); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
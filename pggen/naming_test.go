@@ -0,0 +1,221 @@
+package pggen
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSingular(t *testing.T) {
+	tests := []struct {
+		word     string
+		expected string
+	}{
+		{word: "projects", expected: "project"},
+		{word: "categories", expected: "category"},
+		{word: "companies", expected: "company"},
+		{word: "movies", expected: "movie"},
+		{word: "cookies", expected: "cookie"},
+		{word: "pies", expected: "pie"},
+		{word: "keys", expected: "key"},
+		{word: "statuses", expected: "status"},
+		{word: "bonuses", expected: "bonus"},
+		{word: "campuses", expected: "campus"},
+		{word: "houses", expected: "house"},
+		{word: "causes", expected: "cause"},
+		{word: "analyses", expected: "analysis"},
+		{word: "responses", expected: "response"},
+		{word: "databases", expected: "database"},
+		{word: "addresses", expected: "address"},
+		{word: "boxes", expected: "box"},
+		{word: "batches", expected: "batch"},
+		{word: "wishes", expected: "wish"},
+		{word: "buzzes", expected: "buzz"},
+		{word: "people", expected: "person"},
+		{word: "children", expected: "child"},
+		{word: "indices", expected: "index"},
+		{word: "prices", expected: "price"},
+		{word: "leaves", expected: "leaf"},
+		{word: "archives", expected: "archive"},
+		{word: "heroes", expected: "hero"},
+		{word: "shoes", expected: "shoe"},
+		{word: "series", expected: "series"},
+		{word: "species", expected: "species"},
+		{word: "data", expected: "data"},
+		{word: "status", expected: "status"},
+		{word: "address", expected: "address"},
+		{word: "analysis", expected: "analysis"},
+		{word: "person", expected: "person"},
+		{word: "s", expected: "s"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.word, func(t *testing.T) {
+			if actual := singular(test.word); actual != test.expected {
+				t.Errorf("expected [%s], got [%s]", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestGoIdentifier(t *testing.T) {
+	tests := []struct {
+		name        string
+		initialisms []string
+		expected    string
+	}{
+		{name: "owner_id", expected: "OwnerId"},
+		{name: "owner_id", initialisms: []string{"ID"}, expected: "OwnerID"},
+		{name: "owner_id", initialisms: []string{"id"}, expected: "OwnerID"},
+		{name: "id", initialisms: []string{"ID"}, expected: "ID"},
+		{name: "avatar_url", initialisms: []string{"ID", "URL"}, expected: "AvatarURL"},
+		{name: "identity", initialisms: []string{"ID"}, expected: "Identity"},
+		{name: "api_key_id", initialisms: []string{"API", "ID"}, expected: "APIKeyID"},
+		{name: "ownerId", initialisms: []string{"ID"}, expected: "OwnerID"},
+		{name: "projects_owner_id_fkey", initialisms: []string{"ID"}, expected: "ProjectsOwnerIDFkey"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name+"/"+strings.Join(test.initialisms, ","), func(t *testing.T) {
+			naming := ConfigNaming{Initialisms: test.initialisms}
+			if actual := naming.goIdentifier(test.name); actual != test.expected {
+				t.Errorf("expected [%s], got [%s]", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestTableNames(t *testing.T) {
+	naming := ConfigNaming{
+		Initialisms:   []string{"API"},
+		Singular:      true,
+		StripPrefixes: []string{"tb_", "app_"},
+		Tables:        map[string]string{"people_data": "Person"},
+	}
+
+	tests := []struct {
+		table      string
+		entity     string
+		collection string
+	}{
+		{table: "projects", entity: "Project", collection: "Projects"},
+		{table: "tb_user_addresses", entity: "UserAddress", collection: "UserAddresses"},
+		{table: "app_categories", entity: "Category", collection: "Categories"},
+		{table: "api_keys", entity: "APIKey", collection: "APIKeys"},
+		{table: "tb_", entity: "Tb", collection: "Tb"},
+		{table: "user_metadata", entity: "UserMetadata", collection: "UserMetadata"},
+		{table: "people_data", entity: "Person", collection: "Person"},
+		{table: "people", entity: "Person", collection: "People"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.table, func(t *testing.T) {
+			if actual := naming.entityName(test.table); actual != test.entity {
+				t.Errorf("expected entity [%s], got [%s]", test.entity, actual)
+			}
+
+			if actual := naming.collectionName(test.table); actual != test.collection {
+				t.Errorf("expected collection [%s], got [%s]", test.collection, actual)
+			}
+		})
+	}
+}
+
+func TestFieldName(t *testing.T) {
+	naming := ConfigNaming{
+		Initialisms: []string{"ID"},
+		Columns:     map[string]map[string]string{"projects": {"type": "Kind"}},
+	}
+
+	tests := []struct {
+		table    string
+		column   string
+		expected string
+	}{
+		{table: "projects", column: "owner_id", expected: "OwnerID"},
+		{table: "projects", column: "type", expected: "Kind"},
+		{table: "users", column: "type", expected: "Type"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.table+"."+test.column, func(t *testing.T) {
+			if actual := naming.fieldName(test.table, test.column); actual != test.expected {
+				t.Errorf("expected [%s], got [%s]", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestApplyNamingConstraints(t *testing.T) {
+	model := &PgSchema{
+		Name: "public",
+		Tables: []PgTable{{
+			Kind: _table,
+			Name: "projects",
+			Constraints: []PgConstraint{
+				{Name: "projects_pkey", Type: _primaryKeyConstraint},
+				{Name: "projects_owner_id_fkey", Type: _foreignKeyConstraint},
+				{Name: "projects", Type: _uniqueConstraint},
+				{Name: "api_id_unique", Type: _uniqueConstraint},
+			},
+		}},
+	}
+
+	schema := ConfigSchema{Naming: ConfigNaming{Initialisms: []string{"ID", "API"}, Singular: true}}
+	if err := applyNaming(model, schema); err != nil {
+		t.Fatalf("expected no error, got [%s]", err.Error())
+	}
+
+	expected := []string{
+		"ProjectPkeyConstraint",
+		"ProjectOwnerIDFkeyConstraint",
+		"ProjectProjectsConstraint",
+		"ProjectAPIIDUniqueConstraint",
+	}
+
+	table := &model.Tables[0]
+	for i, constraint := range table.Constraints {
+		if actual := constraint.goName(table); actual != expected[i] {
+			t.Errorf("expected [%s], got [%s]", expected[i], actual)
+		}
+	}
+}
+
+func TestApplyNamingErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		tables []PgTable
+		naming ConfigNaming
+		err    string
+	}{
+		{
+			name:   "entities with the same name",
+			tables: []PgTable{{Kind: _table, Name: "project"}, {Kind: _table, Name: "projects"}},
+			naming: ConfigNaming{Singular: true},
+			err:    "table [projects] and table [project] have the same Go name [Project]",
+		},
+		{
+			name: "fields with the same name",
+			tables: []PgTable{{
+				Kind:    _table,
+				Name:    "projects",
+				Columns: []PgColumn{{Name: "owner_id"}, {Name: "ownerId"}},
+			}},
+			err: "columns [owner_id] and [ownerId] of table [projects] have the same Go name [OwnerId]",
+		},
+		{
+			name:   "entity that is not an identifier",
+			tables: []PgTable{{Kind: _view, Name: "2024_sales"}},
+			err:    "view [2024_sales] has the Go name [2024Sales], which is not a valid identifier",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			model := &PgSchema{Name: "public", Tables: test.tables}
+			err := applyNaming(model, ConfigSchema{Naming: test.naming})
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Errorf("expected error containing [%s], got [%v]", test.err, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,424 @@
+package pggen
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// The actions of a FileChange
+const (
+	CreateFile    = "create"
+	UpdateFile    = "update"
+	DeleteFile    = "delete"
+	UnchangedFile = "unchanged"
+)
+
+// _generatedFileHeaders are the headers of the files generated by pg_gen,
+// in the comment syntax of each language
+var _generatedFileHeaders = []string{
+	"// Code generated by pg_gen",
+	"# Code generated by pg_gen",
+	"%% Code generated by pg_gen",
+	"<!-- Code generated by pg_gen",
+	"{\n  \"$comment\": \"Code generated by pg_gen",
+}
+
+// GeneratedFile is a rendered file, Source describes what it was generated for
+type GeneratedFile struct {
+	Path    string
+	Content []byte
+	Source  string
+}
+
+// FileChange is what writing a generated file does to the destination
+// directory, Previous holds the content on disk
+type FileChange struct {
+	Action   string
+	Reason   string
+	Path     string
+	Previous []byte
+	File     GeneratedFile
+}
+
+func (fc *FileChange) IsStale() bool {
+	return fc.Action != UnchangedFile
+}
+
+func (pcg *PgCodeGenerator) addFile(path string, content []byte, source string) {
+	pcg.files = append(pcg.files, GeneratedFile{
+		Path:    path,
+		Content: content,
+		Source:  source,
+	})
+}
+
+func readExistingFile(path string) ([]byte, bool, error) {
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, false, nil
+	}
+
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read file [%s], got error [%s]", path, err.Error())
+	}
+
+	return content, true, nil
+}
+
+// PlanChanges compares the generated files with the ones on disk, listing
+// what writing them would do without touching the destination directories
+func (pcg *PgCodeGenerator) PlanChanges(files []GeneratedFile) ([]FileChange, error) {
+	changes := make([]FileChange, 0, len(files))
+	for _, file := range files {
+		previous, exists, err := readExistingFile(file.Path)
+		if err != nil {
+			return nil, err
+		}
+
+		change := FileChange{
+			Action:   UnchangedFile,
+			Reason:   "generated code for " + file.Source + " is up to date",
+			Path:     file.Path,
+			Previous: previous,
+			File:     file,
+		}
+
+		if !exists {
+			change.Action = CreateFile
+			change.Reason = "new generated code for " + file.Source
+		} else if !bytes.Equal(previous, file.Content) {
+			change.Action = UpdateFile
+			change.Reason = "generated code for " + file.Source + " changed"
+		}
+
+		changes = append(changes, change)
+	}
+
+	orphans, err := pcg.orphanedFiles(files)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, orphan := range orphans {
+		previous, _, err := readExistingFile(orphan)
+		if err != nil {
+			return nil, err
+		}
+
+		changes = append(changes, FileChange{
+			Action:   DeleteFile,
+			Reason:   "orphaned generated file, its object was removed or ignored",
+			Path:     orphan,
+			Previous: previous,
+		})
+	}
+
+	return changes, nil
+}
+
+func isGeneratedFile(path string) (bool, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	header := make([]byte, 64)
+	n, err := io.ReadFull(file, header)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return false, nil
+	}
+
+	for _, prefix := range _generatedFileHeaders {
+		if bytes.HasPrefix(header[:n], []byte(prefix)) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// orphanedFiles lists the files owned by pg_gen, identified by the generated
+// code header, on the destination directories that were not generated again
+func (pcg *PgCodeGenerator) orphanedFiles(files []GeneratedFile) ([]string, error) {
+	generatedPaths := make(map[string]bool, len(files))
+	for _, file := range files {
+		generatedPaths[filepath.Clean(file.Path)] = true
+	}
+
+	keepOrphans := make(map[string]bool)
+	var patterns []string
+	addDirectory := func(directory, extension string, keep bool) {
+		pattern := filepath.Join(filepath.Clean(directory), "*"+extension)
+		if _, ok := keepOrphans[pattern]; !ok {
+			patterns = append(patterns, pattern)
+		}
+
+		keepOrphans[pattern] = keepOrphans[pattern] || keep
+	}
+
+	for _, schemaName := range pcg.schemaNames() {
+		schema := pcg.cfg.Schemas[schemaName]
+		if schema.GO != nil {
+			addDirectory(schema.GO.Dest, ".go", schema.GO.KeepOrphanedFiles)
+		}
+
+		if schema.TypeScript != nil {
+			addDirectory(schema.TypeScript.Dest, ".ts", schema.TypeScript.KeepOrphanedFiles)
+		}
+
+		if schema.JsonSchema != nil {
+			addDirectory(schema.JsonSchema.Dest, ".schema.json", schema.JsonSchema.KeepOrphanedFiles)
+		}
+
+		if schema.Proto != nil {
+			addDirectory(schema.Proto.Dest, ".proto", schema.Proto.KeepOrphanedFiles)
+		}
+
+		if schema.Python != nil {
+			addDirectory(schema.Python.Dest, ".py", schema.Python.KeepOrphanedFiles)
+		}
+
+		if schema.Docs != nil {
+			for _, extension := range []string{".md", ".mmd", ".dot"} {
+				addDirectory(schema.Docs.Dest, extension, schema.Docs.KeepOrphanedFiles)
+			}
+		}
+	}
+
+	var orphans []string
+	for _, plugin := range pcg.cfg.Plugins {
+		if plugin.KeepOrphanedFiles {
+			continue
+		}
+
+		paths, err := previousPluginFiles(plugin)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, path := range paths {
+			if generatedPaths[path] || slices.Contains(orphans, path) {
+				continue
+			}
+
+			if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+
+			generated, err := isGeneratedFile(path)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read file [%s], got error [%s]", path, err.Error())
+			}
+
+			if generated {
+				orphans = append(orphans, path)
+			}
+		}
+	}
+
+	for _, pattern := range patterns {
+		if keepOrphans[pattern] {
+			continue
+		}
+
+		paths, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, path := range paths {
+			if generatedPaths[filepath.Clean(path)] || slices.Contains(orphans, path) {
+				continue
+			}
+
+			generated, err := isGeneratedFile(path)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read file [%s], got error [%s]", path, err.Error())
+			}
+
+			if generated {
+				orphans = append(orphans, path)
+			}
+		}
+	}
+
+	return orphans, nil
+}
+
+func PrintChanges(w io.Writer, changes []FileChange) {
+	for _, change := range changes {
+		fmt.Fprintf(w, "%-9s %s (%s)\n", change.Action, change.Path, change.Reason)
+	}
+}
+
+func PrintFiles(w io.Writer, files []GeneratedFile) {
+	for _, file := range files {
+		fmt.Fprintf(w, "==> %s <==\n", file.Path)
+		w.Write(file.Content)
+		fmt.Fprintln(w)
+	}
+}
+
+// stagedFile is a change waiting to be swapped into place, TempPath holds
+// the new content, empty for deletions, and BackupPath the original file
+// once it was moved away
+type stagedFile struct {
+	TempPath   string
+	BackupPath string
+	Swapped    bool
+	Change     FileChange
+}
+
+// ApplyChanges writes the changes in two phases, every file is first staged
+// to a temporary file next to its destination and only when all of them were
+// written they are swapped into place. The original files are moved to
+// backups while swapping, so when a swap fails the ones already done are
+// rolled back and the destination directories are left untouched
+func (pcg *PgCodeGenerator) ApplyChanges(changes []FileChange) error {
+	var staged []stagedFile
+	removeStaged := func() {
+		for _, file := range staged {
+			if !strIsEmpty(file.TempPath) {
+				os.Remove(file.TempPath)
+			}
+		}
+	}
+
+	for _, change := range changes {
+		switch change.Action {
+		case CreateFile, UpdateFile:
+			tempPath, err := pcg.stageFile(change.Path, change.File.Content)
+			if err != nil {
+				removeStaged()
+				return fmt.Errorf("failed to write file [%s], got error [%s]", change.Path, err.Error())
+			}
+
+			staged = append(staged, stagedFile{
+				TempPath: tempPath,
+				Change:   change,
+			})
+
+		case DeleteFile:
+			staged = append(staged, stagedFile{
+				Change: change,
+			})
+		}
+	}
+
+	for i := range staged {
+		if err := staged[i].swap(); err != nil {
+			rollback(staged[:i+1])
+			removeStaged()
+			return fmt.Errorf("failed to write file [%s], no file was changed, got error [%s]", staged[i].Change.Path, err.Error())
+		}
+	}
+
+	for _, file := range staged {
+		if !strIsEmpty(file.BackupPath) {
+			os.Remove(file.BackupPath)
+		}
+	}
+
+	for _, change := range changes {
+		switch change.Action {
+		case CreateFile, UpdateFile:
+			log.Printf("- Generated [%s] for %s\n", change.Path, change.File.Source)
+
+		case DeleteFile:
+			log.Printf("- Deleted [%s], %s\n", change.Path, change.Reason)
+
+		case UnchangedFile:
+			log.Printf("- Unchanged [%s] for %s\n", change.Path, change.File.Source)
+		}
+	}
+
+	return nil
+}
+
+// swap moves the original file to a backup and the staged file, if any, to
+// its destination
+func (sf *stagedFile) swap() error {
+	if _, err := os.Lstat(sf.Change.Path); err == nil {
+		backup, err := os.CreateTemp(filepath.Dir(sf.Change.Path), "."+filepath.Base(sf.Change.Path)+".*.bak")
+		if err != nil {
+			return err
+		}
+
+		backup.Close()
+		if err := os.Rename(sf.Change.Path, backup.Name()); err != nil {
+			os.Remove(backup.Name())
+			return err
+		}
+
+		sf.BackupPath = backup.Name()
+	}
+
+	if !strIsEmpty(sf.TempPath) {
+		if err := os.Rename(sf.TempPath, sf.Change.Path); err != nil {
+			return err
+		}
+
+		sf.Swapped = true
+	}
+
+	return nil
+}
+
+// rollback restores the original files of the swapped ones, in the reverse
+// order they were swapped
+func rollback(staged []stagedFile) {
+	for i := len(staged) - 1; i >= 0; i-- {
+		file := staged[i]
+		if file.Swapped {
+			os.Remove(file.Change.Path)
+		}
+
+		if !strIsEmpty(file.BackupPath) {
+			if err := os.Rename(file.BackupPath, file.Change.Path); err != nil {
+				log.Printf("- Failed to restore [%s] from [%s], got error [%s]\n", file.Change.Path, file.BackupPath, err.Error())
+			}
+		}
+	}
+}
+
+func (pcg *PgCodeGenerator) stageFile(path string, data []byte) (string, error) {
+	rootDirectory := filepath.Dir(path)
+	if err := os.MkdirAll(rootDirectory, 0777); err != nil {
+		return "", fmt.Errorf("failed to create root directory [%s], got error [%s]", rootDirectory, err.Error())
+	}
+
+	dest, err := os.CreateTemp(rootDirectory, "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return "", err
+	}
+	defer dest.Close()
+
+	if _, err := dest.Write(data); err != nil {
+		os.Remove(dest.Name())
+		return "", err
+	}
+
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	if err := dest.Chmod(mode); err != nil {
+		os.Remove(dest.Name())
+		return "", err
+	}
+
+	if err := dest.Sync(); err != nil {
+		os.Remove(dest.Name())
+		return "", err
+	}
+
+	return dest.Name(), nil
+}
@@ -0,0 +1,149 @@
+package pggen
+
+import (
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestApplyChanges(t *testing.T) {
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	write := func(change FileChange, content string) FileChange {
+		change.File = GeneratedFile{Path: change.Path, Content: []byte(content), Source: "table [test]"}
+		return change
+	}
+
+	tests := []struct {
+		name     string
+		existing map[string]string
+		changes  func(dir string) []FileChange
+		err      string
+		expected map[string]string
+	}{
+		{
+			name:     "creates, updates and deletes files",
+			existing: map[string]string{"a.go": "old a", "b.go": "old b", "c.go": "old c"},
+			changes: func(dir string) []FileChange {
+				return []FileChange{
+					write(FileChange{Action: UpdateFile, Path: filepath.Join(dir, "a.go")}, "new a"),
+					{Action: DeleteFile, Path: filepath.Join(dir, "b.go")},
+					write(FileChange{Action: CreateFile, Path: filepath.Join(dir, "sub", "d.go")}, "new d"),
+					write(FileChange{Action: UnchangedFile, Path: filepath.Join(dir, "c.go")}, "old c"),
+				}
+			},
+			expected: map[string]string{"a.go": "new a", "c.go": "old c", "sub/d.go": "new d"},
+		},
+		{
+			name:     "rolls back the swapped files when a swap fails",
+			existing: map[string]string{"a.go": "old a", "b.go": "old b", "dir/keep.go": "keep"},
+			changes: func(dir string) []FileChange {
+				return []FileChange{
+					write(FileChange{Action: UpdateFile, Path: filepath.Join(dir, "a.go")}, "new a"),
+					{Action: DeleteFile, Path: filepath.Join(dir, "b.go")},
+					write(FileChange{Action: CreateFile, Path: filepath.Join(dir, "c.go")}, "new c"),
+					write(FileChange{Action: UpdateFile, Path: filepath.Join(dir, "dir")}, "not a directory"),
+				}
+			},
+			err:      string(filepath.Separator) + "dir], no file was changed",
+			expected: map[string]string{"a.go": "old a", "b.go": "old b", "dir/keep.go": "keep"},
+		},
+		{
+			name:     "removes the staged files when staging fails",
+			existing: map[string]string{"a.go": "old a", "file": "not a directory"},
+			changes: func(dir string) []FileChange {
+				return []FileChange{
+					write(FileChange{Action: UpdateFile, Path: filepath.Join(dir, "a.go")}, "new a"),
+					write(FileChange{Action: CreateFile, Path: filepath.Join(dir, "file", "b.go")}, "new b"),
+				}
+			},
+			err:      filepath.Join("file", "b.go") + "], got error [failed to create root directory",
+			expected: map[string]string{"a.go": "old a", "file": "not a directory"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for path, content := range test.existing {
+				path = filepath.Join(dir, filepath.FromSlash(path))
+				if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+					t.Fatalf("failed to create directory, got error [%s]", err.Error())
+				}
+
+				if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+					t.Fatalf("failed to write file, got error [%s]", err.Error())
+				}
+			}
+
+			err := (&PgCodeGenerator{}).ApplyChanges(test.changes(dir))
+			switch {
+			case strIsEmpty(test.err) && err != nil:
+				t.Fatalf("expected no error, got [%s]", err.Error())
+			case !strIsEmpty(test.err) && (err == nil || !strings.Contains(err.Error(), test.err)):
+				t.Fatalf("expected error containing [%s], got [%v]", test.err, err)
+			}
+
+			if files := readTree(t, dir); !reflect.DeepEqual(files, test.expected) {
+				t.Errorf("expected files %v, got %v", test.expected, files)
+			}
+		})
+	}
+}
+
+func TestApplyChangesKeepsFileMode(t *testing.T) {
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	path := filepath.Join(t.TempDir(), "a.go")
+	if err := os.WriteFile(path, []byte("old a"), 0o600); err != nil {
+		t.Fatalf("failed to write file, got error [%s]", err.Error())
+	}
+
+	change := FileChange{Action: UpdateFile, Path: path, File: GeneratedFile{Path: path, Content: []byte("new a")}}
+	if err := (&PgCodeGenerator{}).ApplyChanges([]FileChange{change}); err != nil {
+		t.Fatalf("expected no error, got [%s]", err.Error())
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("failed to stat file, got error [%s]", err.Error())
+	}
+
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("expected mode [%s], got [%s]", os.FileMode(0o600), info.Mode().Perm())
+	}
+}
+
+// readTree returns the content of the files under the directory by their
+// slash separated relative path, including staged and backup files
+func readTree(t *testing.T, dir string) map[string]string {
+	t.Helper()
+
+	files := make(map[string]string)
+	err := filepath.WalkDir(dir, func(path string, entry os.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		relative, err := filepath.Rel(dir, path)
+		files[filepath.ToSlash(relative)] = string(content)
+
+		return err
+	})
+	if err != nil {
+		t.Fatalf("failed to read directory, got error [%s]", err.Error())
+	}
+
+	return files
+}
@@ -0,0 +1,1144 @@
+package pggen
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	_ "embed"
+
+	"github.com/iancoleman/strcase"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+)
+
+var (
+	//go:embed templates/go/table.txt
+	_tableTemplate string
+
+	//go:embed templates/go/common.txt
+	_commonTemplate string
+
+	//go:embed templates/go/view.txt
+	_viewTemplate string
+
+	_tableGoTemplate  = mustParseTemplate("table", _tableTemplate)
+	_commonGoTemplate = mustParseTemplate("common", _commonTemplate)
+	_viewGoTemplate   = mustParseTemplate("view", _viewTemplate)
+)
+
+const (
+	_table  = "table"
+	_view   = "view"
+	_common = "common"
+)
+
+const (
+	_primaryKeyConstraint = "p"
+	_uniqueConstraint     = "u"
+	_foreignKeyConstraint = "f"
+	_checkConstraint      = "c"
+)
+
+func goDataType(sqlDataType string) string {
+	switch strings.ToUpper(sqlDataType) {
+	case "UUID":
+		return "uuid.UUID"
+	case "VARCHAR", "TEXT":
+		return "string"
+	case "TIMESTAMP", "DATE", "DATETIME":
+		return "time.Time"
+	case "INT4", "INTEGER", "BIGINT", "SMALLINT":
+		return "int64"
+	case "DECIMAL", "FLOAT", "DOUBLE PRECISION":
+		return "float64"
+	case "BOOLEAN":
+		return "bool"
+	default:
+		return "any"
+	}
+}
+
+// PgColumn is a column of a table or view
+type PgColumn struct {
+	Name         string `json:"name,omitempty"`
+	SqlDataType  string `json:"sql_data_type,omitempty"`
+	GoDataType   string `json:"go_data_type,omitempty"`
+	MaxLength    int    `json:"max_length,omitempty"`
+	Precision    int    `json:"precision,omitempty"`
+	Scale        int    `json:"scale,omitempty"`
+	Nullable     bool   `json:"nullable,omitempty"`
+	Default      string `json:"default,omitempty"`
+	IsPrimaryKey bool   `json:"is_primary_key,omitempty"`
+	Comment      string `json:"comment,omitempty"`
+
+	goImport string
+	goField  string
+	goTag    string
+}
+
+func (c *PgColumn) goName() string {
+	if !strIsEmpty(c.goField) {
+		return c.goField
+	}
+
+	return strcase.ToCamel(c.Name)
+}
+
+// goType returns the Go type of the column, a pointer when it is nullable
+func (c *PgColumn) goType() string {
+	if c.Nullable && c.GoDataType != "any" {
+		return "*" + c.GoDataType
+	}
+
+	return c.GoDataType
+}
+
+// PgIndex is a table index that does not back a constraint, Columns holds
+// the indexed columns or expressions
+type PgIndex struct {
+	Name    string   `json:"name,omitempty"`
+	Columns []string `json:"columns,omitempty"`
+	Unique  bool     `json:"unique,omitempty"`
+	Method  string   `json:"method,omitempty"`
+	Where   string   `json:"where,omitempty"`
+}
+
+// PgConstraint is a table constraint, its Type is p for primary keys, u for
+// unique constraints, f for foreign keys and c for checks, whose expression
+// is the Check
+type PgConstraint struct {
+	Name       string   `json:"name,omitempty"`
+	Type       string   `json:"type,omitempty"`
+	Columns    []string `json:"columns,omitempty"`
+	RefSchema  string   `json:"ref_schema,omitempty"`
+	RefTable   string   `json:"ref_table,omitempty"`
+	RefColumns []string `json:"ref_columns,omitempty"`
+	Check      string   `json:"check,omitempty"`
+
+	goConstant string
+}
+
+// shortName returns the name of the constraint without the table name prefix
+func (c *PgConstraint) shortName(table *PgTable) string {
+	name := strings.TrimPrefix(c.Name, table.Name+"_")
+	if strIsEmpty(name) {
+		return c.Name
+	}
+
+	return name
+}
+
+func (c *PgConstraint) goName(table *PgTable) string {
+	if !strIsEmpty(c.goConstant) {
+		return c.goConstant
+	}
+
+	return table.entityName() + strcase.ToCamel(c.shortName(table)) + "Constraint"
+}
+
+// PgTable is a table or a view, as told by its Kind
+type PgTable struct {
+	Kind        string         `json:"kind,omitempty"`
+	Name        string         `json:"name,omitempty"`
+	Comment     string         `json:"comment,omitempty"`
+	Columns     []PgColumn     `json:"columns,omitempty"`
+	Constraints []PgConstraint `json:"constraints,omitempty"`
+	Indexes     []PgIndex      `json:"indexes,omitempty"`
+
+	references   []pgRelation
+	referencedBy []pgRelation
+	goEntity     string
+	goCollection string
+	goTags       []ConfigTag
+}
+
+func (t *PgTable) entityName() string {
+	if !strIsEmpty(t.goEntity) {
+		return t.goEntity
+	}
+
+	return strcase.ToCamel(t.Name)
+}
+
+// collectionName returns the Go name of many rows of the table
+func (t *PgTable) collectionName() string {
+	if !strIsEmpty(t.goCollection) {
+		return t.goCollection
+	}
+
+	return t.entityName()
+}
+
+func (t *PgTable) goFilepath(rootDirectory string) string {
+	var sb strings.Builder
+	sb.WriteString(rootDirectory)
+	sb.WriteString("/")
+	sb.WriteString(strcase.ToSnake(t.Name))
+	sb.WriteString(".go")
+
+	return filepath.Clean(sb.String())
+}
+
+func (t *PgTable) goEntityFields() string {
+	var sb strings.Builder
+
+	for _, col := range t.Columns {
+		sb.WriteString(col.goName())
+		sb.WriteString(" ")
+		sb.WriteString(col.goType())
+		sb.WriteString(col.goTag)
+
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
+
+func (t *PgTable) sqlPrimaryKey() string {
+	for _, col := range t.Columns {
+		if col.IsPrimaryKey {
+			return col.Name
+		}
+	}
+
+	return "id" // assumed default
+}
+
+func (t *PgTable) sqlTableName() string {
+	return t.Name
+}
+
+func (t *PgTable) sqlSelectFields() string {
+	var sb strings.Builder
+
+	colSize := len(t.Columns) - 1
+	for i, col := range t.Columns {
+		sb.WriteString("\"")
+		sb.WriteString(col.Name)
+		sb.WriteString("\"")
+
+		if i < colSize {
+			sb.WriteString(", ")
+		}
+	}
+
+	return sb.String()
+}
+
+// goFieldList returns the Go fields of every column, each one preceded by the
+// prefix (ex: &entity.)
+func (t *PgTable) goFieldList(prefix string) string {
+	var sb strings.Builder
+
+	colSize := len(t.Columns) - 1
+	for i, col := range t.Columns {
+		sb.WriteString(prefix)
+		sb.WriteString(col.goName())
+
+		if i < colSize {
+			sb.WriteString(", ")
+		}
+	}
+
+	return sb.String()
+}
+
+func (t *PgTable) goSelectManyScanFields() string {
+	return t.goFieldList("&entity.")
+}
+
+func (t *PgTable) sqlInsertPlaceholders() string {
+	var sb strings.Builder
+
+	colSize := len(t.Columns) - 1
+	for i, col := range t.Columns {
+		sb.WriteString("$")
+		sb.WriteString(strconv.Itoa(i + 1))
+		sb.WriteString("::")
+		sb.WriteString(col.SqlDataType)
+
+		if i < colSize {
+			sb.WriteString(", ")
+		}
+	}
+
+	return sb.String()
+}
+
+func (t *PgTable) sqlUpdatePlaceholders() string {
+	var sb strings.Builder
+
+	colSize := len(t.Columns) - 1
+	position := 2 // $1 Is used for the primary key filter
+	for i, col := range t.Columns {
+		if col.IsPrimaryKey {
+			continue
+		}
+
+		sb.WriteString("\"")
+		sb.WriteString(col.Name)
+		sb.WriteString("\" = $")
+		sb.WriteString(strconv.Itoa(position))
+		sb.WriteString("::")
+		sb.WriteString(col.SqlDataType)
+
+		if i < colSize {
+			sb.WriteString(", ")
+		}
+
+		position++
+	}
+
+	return sb.String()
+}
+
+func (t *PgTable) column(name string) *PgColumn {
+	for i := range t.Columns {
+		if t.Columns[i].Name == name {
+			return &t.Columns[i]
+		}
+	}
+
+	return nil
+}
+
+func (t *PgTable) constraint(name string) *PgConstraint {
+	for i := range t.Constraints {
+		if t.Constraints[i].Name == name {
+			return &t.Constraints[i]
+		}
+	}
+
+	return nil
+}
+
+func (t *PgTable) primaryKey() *PgConstraint {
+	for i := range t.Constraints {
+		if t.Constraints[i].Type == _primaryKeyConstraint {
+			return &t.Constraints[i]
+		}
+	}
+
+	return nil
+}
+
+func (t *PgTable) foreignKeys() []PgConstraint {
+	var foreignKeys []PgConstraint
+	for _, constraint := range t.Constraints {
+		if constraint.Type == _foreignKeyConstraint {
+			foreignKeys = append(foreignKeys, constraint)
+		}
+	}
+
+	return foreignKeys
+}
+
+// PgEnum is an enum type and its values, in their sort order
+type PgEnum struct {
+	Name   string   `json:"name,omitempty"`
+	Values []string `json:"values,omitempty"`
+}
+
+// PgSchema is the model of a database schema
+type PgSchema struct {
+	Name      string       `json:"name,omitempty"`
+	Tables    []PgTable    `json:"tables,omitempty"`
+	Enums     []PgEnum     `json:"enums,omitempty"`
+	Functions []PgFunction `json:"functions,omitempty"`
+	Queries   []PgQuery    `json:"queries,omitempty"`
+}
+
+// PgCodeGenerator introspects and generates the code of the configured
+// schemas
+type PgCodeGenerator struct {
+	db    *sql.DB
+	cfg   *Config
+	files []GeneratedFile
+}
+
+// NewPgCodeGenerator returns a generator for the config, opening the database
+// unless a schema file or migrations are configured
+func NewPgCodeGenerator(cfg *Config) (*PgCodeGenerator, error) {
+	if !strIsEmpty(cfg.SchemaFile) || !strIsEmpty(cfg.Migrations) {
+		return &PgCodeGenerator{
+			cfg: cfg,
+		}, nil
+	}
+
+	db, err := sql.Open("pgx", cfg.DSN)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PgCodeGenerator{
+		db:  db,
+		cfg: cfg,
+	}, nil
+}
+
+func (pcg *PgCodeGenerator) Close() error {
+	if pcg.db == nil {
+		return nil
+	}
+
+	return pcg.db.Close()
+}
+
+// Generate introspects and renders the schemas, then writes the changed files
+func (pcg *PgCodeGenerator) Generate(ctx context.Context) error {
+	files, err := pcg.IntrospectAndRender(ctx)
+	if err != nil {
+		return err
+	}
+
+	changes, err := pcg.PlanChanges(files)
+	if err != nil {
+		return err
+	}
+
+	return pcg.ApplyChanges(changes)
+}
+
+func (pcg *PgCodeGenerator) IntrospectAndRender(ctx context.Context) ([]GeneratedFile, error) {
+	schemas, err := pcg.Introspect(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return pcg.Render(ctx, schemas)
+}
+
+func (pcg *PgCodeGenerator) schemaNames() []string {
+	names := make([]string, 0, len(pcg.cfg.Schemas))
+	for name := range pcg.cfg.Schemas {
+		names = append(names, name)
+	}
+
+	slices.Sort(names)
+
+	return names
+}
+
+// Introspect returns the model of the configured schemas
+func (pcg *PgCodeGenerator) Introspect(ctx context.Context) ([]PgSchema, error) {
+	if !strIsEmpty(pcg.cfg.SchemaFile) {
+		return pcg.loadSchemaSnapshot(pcg.cfg.SchemaFile)
+	}
+
+	if !strIsEmpty(pcg.cfg.Migrations) {
+		return pcg.loadMigrations(pcg.cfg.Migrations)
+	}
+
+	var schemas []PgSchema
+	for _, schemaName := range pcg.schemaNames() {
+		schema, err := pcg.introspectSchema(ctx, schemaName, pcg.cfg.Schemas[schemaName])
+		if err != nil {
+			return nil, err
+		}
+
+		schemas = append(schemas, schema)
+	}
+
+	return schemas, nil
+}
+
+func (pcg *PgCodeGenerator) introspectSchema(ctx context.Context, schemaName string, schema ConfigSchema) (PgSchema, error) {
+	tables, err := pcg.getPgTables(ctx, schemaName)
+	if err != nil {
+		return PgSchema{}, err
+	}
+
+	if schema.IncludeViews {
+		views, err := pcg.getPgViews(ctx, schemaName)
+		if err != nil {
+			return PgSchema{}, err
+		}
+
+		for _, view := range views {
+			tables = append(tables, view)
+		}
+	}
+
+	enums, err := pcg.getPgEnums(ctx, schemaName)
+	if err != nil {
+		return PgSchema{}, err
+	}
+
+	var functions []PgFunction
+	if schema.IncludeFunctions {
+		functions, err = pcg.getPgFunctions(ctx, schemaName)
+		if err != nil {
+			return PgSchema{}, err
+		}
+	}
+
+	var queries []PgQuery
+	if !strIsEmpty(schema.Queries) {
+		queries, err = loadQueries(schema.Queries)
+		if err != nil {
+			return PgSchema{}, err
+		}
+
+		for i := range queries {
+			if err := pcg.describeQuery(ctx, &queries[i]); err != nil {
+				return PgSchema{}, err
+			}
+		}
+	}
+
+	return PgSchema{
+		Name:      schemaName,
+		Tables:    tables,
+		Enums:     enums,
+		Functions: functions,
+		Queries:   queries,
+	}, nil
+}
+
+// Render returns the files generated for the model
+func (pcg *PgCodeGenerator) Render(ctx context.Context, schemas []PgSchema) ([]GeneratedFile, error) {
+	pcg.files = nil
+
+	annotated := make([]PgSchema, 0, len(schemas))
+	for _, model := range schemas {
+		schema, ok := pcg.cfg.Schemas[model.Name]
+		if !ok {
+			return nil, fmt.Errorf("schema [%s] is not present in $.schemas", model.Name)
+		}
+
+		model, err := applyAnnotations(model)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := applyNaming(&model, schema); err != nil {
+			return nil, err
+		}
+
+		annotated = append(annotated, model)
+
+		if schema.GO != nil {
+			if err := pcg.renderGo(&model, schema); err != nil {
+				return nil, err
+			}
+		}
+
+		if schema.TypeScript != nil {
+			if err := pcg.generateCodeForTypeScript(&model, schema); err != nil {
+				return nil, err
+			}
+		}
+
+		if schema.JsonSchema != nil {
+			if err := pcg.generateCodeForJsonSchema(&model, schema); err != nil {
+				return nil, err
+			}
+		}
+
+		if schema.Proto != nil {
+			if err := pcg.generateCodeForProto(&model, schema); err != nil {
+				return nil, err
+			}
+		}
+
+		if schema.Python != nil {
+			if err := pcg.generateCodeForPython(&model, schema); err != nil {
+				return nil, err
+			}
+		}
+
+		if schema.Docs != nil {
+			if err := pcg.generateDocs(&model, schema); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := pcg.generateCodeForPlugins(ctx, annotated); err != nil {
+		return nil, err
+	}
+
+	return pcg.files, nil
+}
+
+func (pcg *PgCodeGenerator) renderGo(model *PgSchema, schema ConfigSchema) error {
+	linkRelations(model.Tables, model.Name, schema)
+
+	names, err := declareGoNames(model, schema)
+	if err != nil {
+		return err
+	}
+
+	err = pcg.generateCodeForTables(
+		model,
+		schema,
+		schema.GO.Dest,
+		schema.GO.Package,
+		schema.GO.EmitJsonTags)
+	if err != nil {
+		return err
+	}
+
+	if len(model.Functions) > 0 {
+		err = pcg.generateCodeForFunctions(
+			model.Functions,
+			model.Tables,
+			model.Name,
+			schema,
+			names,
+			schema.GO.Dest,
+			schema.GO.Package,
+			schema.GO.structTags())
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(model.Queries) > 0 {
+		err = pcg.generateCodeForQueries(
+			model.Queries,
+			model.Tables,
+			schema,
+			names,
+			schema.GO.Dest,
+			schema.GO.Package,
+			schema.GO.structTags())
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// DatabaseSchemaNames lists the schemas of the database, without the system
+// ones
+func (pcg *PgCodeGenerator) DatabaseSchemaNames(ctx context.Context) ([]string, error) {
+	const query = `
+	SELECT
+		n.nspname
+	FROM
+		pg_catalog.pg_namespace n
+	WHERE
+		n.nspname NOT LIKE 'pg\_%'
+		AND n.nspname <> 'information_schema'
+	ORDER BY
+		n.nspname
+	`
+
+	rows, err := pcg.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var schemaNames []string
+	for rows.Next() {
+		var schemaName string
+		if err := rows.Scan(&schemaName); err != nil {
+			return nil, err
+		}
+
+		schemaNames = append(schemaNames, schemaName)
+	}
+
+	return schemaNames, nil
+}
+
+func (pcg *PgCodeGenerator) pgTableFromRows(rows *sql.Rows, kind string) ([]PgTable, error) {
+	var tables []PgTable
+	for rows.Next() {
+		var table PgTable
+		var columnsJson []byte
+
+		if err := rows.Scan(&table.Name, &table.Comment, &columnsJson); err != nil {
+			return nil, err
+		}
+
+		if err := json.Unmarshal(columnsJson, &table.Columns); err != nil {
+			return nil, err
+		}
+
+		for i := range table.Columns {
+			table.Columns[i].GoDataType = goDataType(table.Columns[i].SqlDataType)
+		}
+
+		table.Kind = kind
+		tables = append(tables, table)
+	}
+
+	return tables, nil
+}
+
+func (pcg *PgCodeGenerator) getPgTables(ctx context.Context, schema string) ([]PgTable, error) {
+	const query = `
+	SELECT
+		t.tablename AS name,
+		COALESCE(obj_description(format('%I.%I', t.schemaname, t.tablename)::regclass, 'pg_class'), '') AS comment,
+		json_agg(json_build_object(
+			'name', c.column_name,
+			'nullable', (c.is_nullable = 'YES'),
+			'sql_data_type', UPPER(c.udt_name),
+			'max_length', c.character_maximum_length,
+			'precision', CASE WHEN c.udt_name = 'numeric' THEN c.numeric_precision END,
+			'scale', CASE WHEN c.udt_name = 'numeric' THEN c.numeric_scale END,
+			'default', c.column_default,
+			'is_primary_key', (ccu.column_name IS NOT NULL),
+			'comment', col_description(format('%I.%I', t.schemaname, t.tablename)::regclass, c.ordinal_position)
+		) ORDER BY c.ordinal_position) AS columns
+	FROM
+		pg_catalog.pg_tables t
+	INNER JOIN information_schema.columns c ON
+		c.table_name = t.tablename
+	LEFT JOIN information_schema.table_constraints tc ON
+		tc.table_name = t.tablename
+		AND tc.constraint_type = 'PRIMARY KEY'
+	LEFT JOIN information_schema.constraint_column_usage ccu ON
+		ccu.constraint_name = tc.constraint_name
+		AND ccu.constraint_schema = tc.constraint_schema
+		AND ccu.column_name = c.column_name
+	WHERE
+		t.schemaname = $1
+	GROUP BY
+		t.schemaname,
+		t.tablename
+	`
+
+	// Tables
+	rows, err := pcg.db.QueryContext(ctx, query, schema)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tables, err := pcg.pgTableFromRows(rows, _table)
+	if err != nil {
+		return nil, err
+	}
+
+	constraints, err := pcg.getPgConstraints(ctx, schema)
+	if err != nil {
+		return nil, err
+	}
+
+	indexes, err := pcg.getPgIndexes(ctx, schema)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range tables {
+		tables[i].Constraints = constraints[tables[i].Name]
+		tables[i].Indexes = indexes[tables[i].Name]
+	}
+
+	return tables, nil
+}
+
+// getPgIndexes returns the indexes of the tables, leaving out the ones
+// created for primary key, unique and exclusion constraints
+func (pcg *PgCodeGenerator) getPgIndexes(ctx context.Context, schema string) (map[string][]PgIndex, error) {
+	const query = `
+	SELECT
+		t.relname AS table_name,
+		i.relname AS name,
+		(
+			SELECT
+				json_agg(pg_get_indexdef(ix.indexrelid, k.n, true) ORDER BY k.n)
+			FROM
+				generate_series(1, ix.indnkeyatts) AS k(n)
+		) AS columns,
+		ix.indisunique AS is_unique,
+		am.amname AS method,
+		COALESCE(pg_get_expr(ix.indpred, ix.indrelid, true), '') AS predicate
+	FROM
+		pg_index ix
+	INNER JOIN pg_class i ON
+		i.oid = ix.indexrelid
+	INNER JOIN pg_class t ON
+		t.oid = ix.indrelid
+	INNER JOIN pg_namespace n ON
+		n.oid = t.relnamespace
+	INNER JOIN pg_am am ON
+		am.oid = i.relam
+	WHERE
+		n.nspname = $1
+		AND NOT EXISTS (
+			SELECT
+				1
+			FROM
+				pg_constraint con
+			WHERE
+				con.conindid = ix.indexrelid
+				AND con.conrelid = ix.indrelid
+				AND con.contype IN ('p', 'u', 'x')
+		)
+	ORDER BY
+		t.relname,
+		i.relname
+	`
+
+	rows, err := pcg.db.QueryContext(ctx, query, schema)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	indexes := make(map[string][]PgIndex)
+	for rows.Next() {
+		var tableName string
+		var index PgIndex
+		var columnsJson []byte
+
+		err := rows.Scan(
+			&tableName,
+			&index.Name,
+			&columnsJson,
+			&index.Unique,
+			&index.Method,
+			&index.Where)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := json.Unmarshal(columnsJson, &index.Columns); err != nil {
+			return nil, err
+		}
+
+		indexes[tableName] = append(indexes[tableName], index)
+	}
+
+	return indexes, nil
+}
+
+func (pcg *PgCodeGenerator) getPgConstraints(ctx context.Context, schema string) (map[string][]PgConstraint, error) {
+	const query = `
+	SELECT
+		cl.relname AS table_name,
+		con.conname AS name,
+		con.contype::text AS type,
+		COALESCE((
+			SELECT
+				json_agg(a.attname ORDER BY k.ord)
+			FROM
+				unnest(con.conkey) WITH ORDINALITY AS k(attnum, ord)
+			INNER JOIN pg_attribute a ON
+				a.attrelid = con.conrelid
+				AND a.attnum = k.attnum
+		), '[]') AS columns,
+		COALESCE(rn.nspname, '') AS ref_schema,
+		COALESCE(rcl.relname, '') AS ref_table,
+		COALESCE((
+			SELECT
+				json_agg(a.attname ORDER BY k.ord)
+			FROM
+				unnest(con.confkey) WITH ORDINALITY AS k(attnum, ord)
+			INNER JOIN pg_attribute a ON
+				a.attrelid = con.confrelid
+				AND a.attnum = k.attnum
+		), '[]') AS ref_columns,
+		CASE
+			WHEN con.contype = 'c' THEN COALESCE(substring(pg_get_constraintdef(con.oid) FROM '^CHECK \((.*)\)'), '')
+			ELSE ''
+		END AS check_expression
+	FROM
+		pg_constraint con
+	INNER JOIN pg_class cl ON
+		cl.oid = con.conrelid
+	INNER JOIN pg_namespace n ON
+		n.oid = cl.relnamespace
+	LEFT JOIN pg_class rcl ON
+		rcl.oid = con.confrelid
+	LEFT JOIN pg_namespace rn ON
+		rn.oid = rcl.relnamespace
+	WHERE
+		n.nspname = $1
+		AND con.contype IN ('p', 'u', 'f', 'c')
+	ORDER BY
+		cl.relname,
+		con.conname
+	`
+
+	rows, err := pcg.db.QueryContext(ctx, query, schema)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	constraints := make(map[string][]PgConstraint)
+	for rows.Next() {
+		var tableName string
+		var constraint PgConstraint
+		var columnsJson []byte
+		var refColumnsJson []byte
+
+		err := rows.Scan(
+			&tableName,
+			&constraint.Name,
+			&constraint.Type,
+			&columnsJson,
+			&constraint.RefSchema,
+			&constraint.RefTable,
+			&refColumnsJson,
+			&constraint.Check)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := json.Unmarshal(columnsJson, &constraint.Columns); err != nil {
+			return nil, err
+		}
+
+		if err := json.Unmarshal(refColumnsJson, &constraint.RefColumns); err != nil {
+			return nil, err
+		}
+
+		constraints[tableName] = append(constraints[tableName], constraint)
+	}
+
+	return constraints, nil
+}
+
+func (pcg *PgCodeGenerator) getPgEnums(ctx context.Context, schema string) ([]PgEnum, error) {
+	const query = `
+	SELECT
+		t.typname AS name,
+		json_agg(e.enumlabel ORDER BY e.enumsortorder) AS values
+	FROM
+		pg_type t
+	INNER JOIN pg_enum e ON
+		e.enumtypid = t.oid
+	INNER JOIN pg_namespace n ON
+		n.oid = t.typnamespace
+	WHERE
+		n.nspname = $1
+	GROUP BY
+		t.typname
+	ORDER BY
+		t.typname
+	`
+
+	rows, err := pcg.db.QueryContext(ctx, query, schema)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var enums []PgEnum
+	for rows.Next() {
+		var enum PgEnum
+		var valuesJson []byte
+
+		if err := rows.Scan(&enum.Name, &valuesJson); err != nil {
+			return nil, err
+		}
+
+		if err := json.Unmarshal(valuesJson, &enum.Values); err != nil {
+			return nil, err
+		}
+
+		enums = append(enums, enum)
+	}
+
+	return enums, nil
+}
+
+func (pcg *PgCodeGenerator) getPgViews(ctx context.Context, schema string) ([]PgTable, error) {
+	const query = `
+	SELECT
+		v.table_name as name,
+		COALESCE(obj_description(c.oid, 'pg_class'), '') as comment,
+		json_agg(json_build_object(
+			'name', a.attname,
+			'sql_data_type', t.typname,
+			'nullable', false,
+			'is_primary_key', false,
+			'comment', col_description(c.oid, a.attnum)
+		) ORDER BY a.attnum) as columns
+	FROM
+		information_schema.views v
+	INNER JOIN pg_class c ON
+		c.relname = v.table_name
+	INNER JOIN pg_attribute a ON
+		a.attrelid = c.oid
+	INNER JOIN pg_type t ON
+		t.oid = a.atttypid
+	WHERE
+		c.relkind = 'v'
+		AND table_schema = $1
+	GROUP BY
+		v.table_name,
+		c.oid;
+	`
+
+	rows, err := pcg.db.QueryContext(ctx, query, schema)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	views, err := pcg.pgTableFromRows(rows, _view)
+	if err != nil {
+		return nil, err
+	}
+
+	return views, nil
+}
+
+func (pcg *PgCodeGenerator) commomFilepath(rootDirectory, packageName string) string {
+	var sb strings.Builder
+	sb.WriteString(rootDirectory)
+	sb.WriteString("/")
+	sb.WriteString(packageName)
+	sb.WriteString(".go")
+
+	return filepath.Clean(sb.String())
+}
+
+func (pcg *PgCodeGenerator) generateGoCommonFile(
+	templates *goTemplates,
+	data *templateData,
+	rootDirectory string,
+) error {
+	path := pcg.commomFilepath(rootDirectory, data.Package)
+
+	code, err := renderGoTemplate(templates.Common, path, data)
+	if err != nil {
+		return fmt.Errorf("failed to generate commoon code for package [%s], got error [%s]", data.Package, err.Error())
+	}
+
+	pcg.addFile(path, code, "package ["+data.Package+"]")
+
+	return nil
+}
+
+func (pcg *PgCodeGenerator) generateGoFile(
+	templates *goTemplates,
+	data *templateData,
+	rootDirectory string,
+) error {
+	template := templates.Table
+	if data.Table.Kind == _view {
+		template = templates.View
+	}
+
+	path := data.Table.goFilepath(rootDirectory)
+
+	code, err := renderGoTemplate(template, path, data)
+	if err != nil {
+		return fmt.Errorf("failed to generate code for %s [%s], got error [%s]", data.Table.Kind, data.Table.Name, err.Error())
+	}
+
+	pcg.addFile(path, code, data.Table.Kind+" ["+data.Table.Name+"]")
+
+	return nil
+}
+
+// generateCustomFile renders a custom template for a table, a view or, when
+// data.Table is nil, the whole schema
+func (pcg *PgCodeGenerator) generateCustomFile(
+	custom customTemplate,
+	data *templateData,
+	rootDirectory string,
+) error {
+	source := "package [" + data.Package + "]"
+	if data.Table != nil {
+		source = data.Table.Kind + " [" + data.Table.Name + "]"
+	}
+
+	output, err := executeTemplate(custom.Output, data)
+	if err != nil {
+		return fmt.Errorf("failed to generate the output path of template [%s] for %s, got error [%s]", custom.Template.Name(), source, err.Error())
+	}
+
+	if strIsEmpty(output) {
+		return fmt.Errorf("template [%s] output path for %s must not be blank", custom.Template.Name(), source)
+	}
+
+	path := filepath.Join(rootDirectory, output)
+
+	code, err := renderGoTemplate(custom.Template, path, data)
+	if err != nil {
+		return fmt.Errorf("failed to generate code with template [%s] for %s, got error [%s]", custom.Template.Name(), source, err.Error())
+	}
+
+	pcg.addFile(path, code, source+" with template ["+custom.Template.Name()+"]")
+
+	return nil
+}
+
+func (pcg *PgCodeGenerator) generateCodeForTables(
+	model *PgSchema,
+	schema ConfigSchema,
+	rootDirectory string,
+	packageName string,
+	emitJsonTags bool,
+) error {
+	log.Printf("Generating code for tables and views")
+
+	if schema.GO != nil {
+		tags := schema.GO.structTags()
+		for i := range model.Tables {
+			model.Tables[i].applyGoTags(tags)
+		}
+
+		templates, err := loadGoTemplates(schema.GO.Templates)
+		if err != nil {
+			return err
+		}
+
+		data := &templateData{
+			Package:        packageName,
+			EmitJsonTags:   emitJsonTags,
+			EmitValidation: schema.GO.EmitValidation,
+			Schema:         model,
+		}
+
+		if err := pcg.generateGoCommonFile(templates, data, rootDirectory); err != nil {
+			return err
+		}
+
+		for _, custom := range templates.Custom {
+			if custom.Kind != _common {
+				continue
+			}
+
+			if err := pcg.generateCustomFile(custom, data, rootDirectory); err != nil {
+				return err
+			}
+		}
+
+		for i := range model.Tables {
+			table := &model.Tables[i]
+			if schema.ShouldIgnore(table.Name) {
+				log.Printf("- Ignored code generation for %s [%s]\n", table.Kind, table.Name)
+				continue
+			}
+
+			tableData := *data
+			tableData.Table = table
+
+			if err := pcg.generateGoFile(templates, &tableData, rootDirectory); err != nil {
+				return err
+			}
+
+			for _, custom := range templates.Custom {
+				if custom.Kind != table.Kind {
+					continue
+				}
+
+				if err := pcg.generateCustomFile(custom, &tableData, rootDirectory); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
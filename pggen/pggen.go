@@ -0,0 +1,46 @@
+// Package pggen introspects Postgres schemas and generates database/sql code
+// for them, it is the library behind the pg_gen command.
+//
+// The model is read with Introspect and rendered with Render, which returns
+// the generated files without writing them. A PgCodeGenerator does both and
+// writes the changed files:
+//
+//	cfg, err := pggen.LoadConfigFromFile("./pg_gen.yaml")
+//	...
+//	model, err := pggen.Introspect(ctx, db, cfg)
+//	...
+//	files, err := pggen.Render(ctx, model, cfg)
+package pggen
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+// Introspect returns the model of the configured schemas. When the config
+// sets a schema file or migrations the model is read from them and db may
+// be nil
+func Introspect(ctx context.Context, db *sql.DB, cfg *Config) ([]PgSchema, error) {
+	if db == nil && strIsEmpty(cfg.SchemaFile) && strIsEmpty(cfg.Migrations) {
+		return nil, errors.New("a database must be given to introspect the schemas, or $.schema_file or $.migrations must be set")
+	}
+
+	pcg := &PgCodeGenerator{
+		db:  db,
+		cfg: cfg,
+	}
+
+	return pcg.Introspect(ctx)
+}
+
+// Render returns the files generated for the model, following the go config
+// of each schema. No file is written or read, except for the configured
+// templates and the proto lock files. The context cancels the plugins
+func Render(ctx context.Context, model []PgSchema, cfg *Config) ([]GeneratedFile, error) {
+	pcg := &PgCodeGenerator{
+		cfg: cfg,
+	}
+
+	return pcg.Render(ctx, model)
+}
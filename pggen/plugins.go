@@ -0,0 +1,202 @@
+package pggen
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// PluginRequest is written as JSON to the stdin of a plugin, Schemas holds
+// the same model written to schema files
+type PluginRequest struct {
+	Schemas []PgSchema     `json:"schemas"`
+	Options map[string]any `json:"options"`
+}
+
+// PluginFile is a file returned by a plugin, its path is relative to the
+// plugin dest
+type PluginFile struct {
+	Path    string `json:"path"`
+	Content string `json:"content"`
+}
+
+// PluginResponse is read as JSON from the stdout of a plugin, a non blank
+// Error fails the generation
+type PluginResponse struct {
+	Files []PluginFile `json:"files"`
+	Error string       `json:"error"`
+}
+
+func (cp *ConfigPlugin) name() string {
+	return filepath.Base(cp.Command)
+}
+
+// manifestPath returns the path of the file listing the files the plugin
+// generated, which are the only ones deleted when the plugin stops returning
+// them
+func (cp *ConfigPlugin) manifestPath() string {
+	return filepath.Join(cp.Dest, "."+cp.name()+".pg_gen.json")
+}
+
+// pluginManifest is the content of the manifest of a plugin, Files are
+// relative to the plugin dest
+type pluginManifest struct {
+	Comment string   `json:"$comment"`
+	Files   []string `json:"files"`
+}
+
+// pluginManifestFile returns the manifest listing the files of the plugin
+func pluginManifestFile(plugin ConfigPlugin, files []GeneratedFile) (GeneratedFile, error) {
+	manifest := pluginManifest{
+		Comment: "Code generated by pg_gen, DO NOT EDIT. Files generated by plugin [" + plugin.name() + "]",
+		Files:   make([]string, 0, len(files)),
+	}
+
+	for _, file := range files {
+		path, err := filepath.Rel(plugin.Dest, file.Path)
+		if err != nil {
+			return GeneratedFile{}, err
+		}
+
+		manifest.Files = append(manifest.Files, filepath.ToSlash(path))
+	}
+
+	slices.Sort(manifest.Files)
+
+	content, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return GeneratedFile{}, fmt.Errorf("failed to encode the manifest of plugin [%s], got error [%s]", plugin.name(), err.Error())
+	}
+
+	return GeneratedFile{
+		Path:    plugin.manifestPath(),
+		Content: append(content, '\n'),
+		Source:  "files of plugin [" + plugin.name() + "]",
+	}, nil
+}
+
+// previousPluginFiles returns the paths listed by the manifest the plugin
+// wrote on its last run, none when it never ran
+func previousPluginFiles(plugin ConfigPlugin) ([]string, error) {
+	content, exists, err := readExistingFile(plugin.manifestPath())
+	if err != nil || !exists {
+		return nil, err
+	}
+
+	var manifest pluginManifest
+	if err := json.Unmarshal(content, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse the manifest [%s] of plugin [%s], got error [%s]", plugin.manifestPath(), plugin.name(), err.Error())
+	}
+
+	var paths []string
+	for _, path := range manifest.Files {
+		if filepath.IsLocal(filepath.FromSlash(path)) {
+			paths = append(paths, filepath.Join(plugin.Dest, filepath.FromSlash(path)))
+		}
+	}
+
+	return paths, nil
+}
+
+// runPlugin sends the model to the plugin and returns the files it generated
+func runPlugin(ctx context.Context, plugin ConfigPlugin, schemas []PgSchema) ([]GeneratedFile, error) {
+	request, err := json.Marshal(PluginRequest{
+		Schemas: schemas,
+		Options: plugin.Options,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode the request of plugin [%s], got error [%s]", plugin.name(), err.Error())
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, plugin.timeout())
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, plugin.Command, plugin.Args...)
+	cmd.Stdin = bytes.NewReader(request)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	// Children of a killed plugin may keep its stdout open, so the output is
+	// not waited for long after it is killed
+	cmd.WaitDelay = time.Second
+
+	if err := cmd.Run(); err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, fmt.Errorf("plugin [%s] did not finish in [%s]", plugin.name(), plugin.timeout())
+		}
+
+		message := strings.TrimSpace(stderr.String())
+		if strIsEmpty(message) {
+			message = err.Error()
+		}
+
+		return nil, fmt.Errorf("plugin [%s] failed, got error [%s]", plugin.name(), message)
+	}
+
+	var response PluginResponse
+	if err := json.Unmarshal(stdout.Bytes(), &response); err != nil {
+		return nil, fmt.Errorf("failed to decode the response of plugin [%s], got error [%s]", plugin.name(), err.Error())
+	}
+
+	if !strIsEmpty(response.Error) {
+		return nil, fmt.Errorf("plugin [%s] failed, got error [%s]", plugin.name(), response.Error)
+	}
+
+	files := make([]GeneratedFile, 0, len(response.Files))
+	for _, file := range response.Files {
+		if strIsEmpty(file.Path) || !filepath.IsLocal(file.Path) {
+			return nil, fmt.Errorf("plugin [%s] returned the invalid path [%s], it must be relative to the plugin dest", plugin.name(), file.Path)
+		}
+
+		files = append(files, GeneratedFile{
+			Path:    filepath.Join(plugin.Dest, file.Path),
+			Content: []byte(file.Content),
+			Source:  "plugin [" + plugin.name() + "]",
+		})
+	}
+
+	return files, nil
+}
+
+func (pcg *PgCodeGenerator) generateCodeForPlugins(ctx context.Context, schemas []PgSchema) error {
+	generated := make(map[string]string, len(pcg.files))
+	for _, file := range pcg.files {
+		generated[filepath.Clean(file.Path)] = file.Source
+	}
+
+	for _, plugin := range pcg.cfg.Plugins {
+		log.Printf("Running plugin [%s]", plugin.name())
+
+		files, err := runPlugin(ctx, plugin, schemas)
+		if err != nil {
+			return err
+		}
+
+		manifest, err := pluginManifestFile(plugin, files)
+		if err != nil {
+			return err
+		}
+
+		files = append(files, manifest)
+
+		for _, file := range files {
+			if source, ok := generated[filepath.Clean(file.Path)]; ok {
+				return fmt.Errorf("plugin [%s] returned the path [%s], which is already generated for %s", plugin.name(), file.Path, source)
+			}
+
+			generated[filepath.Clean(file.Path)] = file.Source
+		}
+
+		pcg.files = append(pcg.files, files...)
+	}
+
+	return nil
+}
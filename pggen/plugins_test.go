@@ -0,0 +1,86 @@
+package pggen
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestPluginOrphanedFiles(t *testing.T) {
+	dest := t.TempDir()
+	plugin := ConfigPlugin{Command: "/usr/bin/docs", Dest: dest}
+	goDest := filepath.Join(dest, "db")
+
+	write := func(path, content string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("failed to create directory, got error [%s]", err.Error())
+		}
+
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("failed to write file, got error [%s]", err.Error())
+		}
+	}
+
+	generated := func(path string) GeneratedFile {
+		return GeneratedFile{Path: filepath.Join(dest, path), Content: []byte("<!-- Code generated by pg_gen -->\n")}
+	}
+
+	previousFiles := []GeneratedFile{generated("a.md"), generated("sub/b.md"), generated("edited.md")}
+	manifest, err := pluginManifestFile(plugin, previousFiles)
+	if err != nil {
+		t.Fatalf("expected no error, got [%s]", err.Error())
+	}
+
+	write(manifest.Path, string(manifest.Content))
+	for _, file := range previousFiles {
+		write(file.Path, string(file.Content))
+	}
+
+	write(filepath.Join(dest, "edited.md"), "hand written\n")
+	write(filepath.Join(dest, "other.md"), "<!-- Code generated by pg_gen -->\n")
+	write(filepath.Join(goDest, "gen.go"), "// Code generated by pg_gen, DO NOT EDIT.\n")
+
+	tests := []struct {
+		name     string
+		keep     bool
+		files    []GeneratedFile
+		expected []string
+	}{
+		{
+			name:     "deletes the files of the last run that were not generated again",
+			files:    []GeneratedFile{generated("a.md"), {Path: filepath.Join(goDest, "gen.go")}},
+			expected: []string{filepath.Join(dest, "sub", "b.md")},
+		},
+		{
+			name:  "keeps every file when configured",
+			keep:  true,
+			files: []GeneratedFile{generated("a.md"), {Path: filepath.Join(goDest, "gen.go")}},
+		},
+		{
+			name:     "keeps the files of other targets",
+			files:    []GeneratedFile{generated("a.md"), generated("sub/b.md")},
+			expected: nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			plugin.KeepOrphanedFiles = test.keep
+			pcg := &PgCodeGenerator{cfg: &Config{
+				Schemas: map[string]ConfigSchema{"public": {GO: &ConfigSchemaGO{Dest: goDest, KeepOrphanedFiles: true}}},
+				Plugins: []ConfigPlugin{plugin},
+			}}
+
+			orphans, err := pcg.orphanedFiles(test.files)
+			if err != nil {
+				t.Fatalf("expected no error, got [%s]", err.Error())
+			}
+
+			if !reflect.DeepEqual(orphans, test.expected) {
+				t.Errorf("expected orphans %v, got %v", test.expected, orphans)
+			}
+		})
+	}
+}
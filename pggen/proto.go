@@ -0,0 +1,349 @@
+package pggen
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/template"
+
+	_ "embed"
+
+	"github.com/iancoleman/strcase"
+)
+
+var (
+	//go:embed templates/proto/schema.txt
+	_protoSchemaTemplate string
+
+	_protoSchemaGoTemplate = template.Must(template.New("proto").Funcs(_templateFuncs).Parse(_protoSchemaTemplate))
+)
+
+const (
+	_protoTimestamp = "google.protobuf.Timestamp"
+	_protoDuration  = "google.protobuf.Duration"
+	_protoValue     = "google.protobuf.Value"
+)
+
+// _protoImports are the files declaring the well known types
+var _protoImports = map[string]string{
+	_protoTimestamp: "google/protobuf/timestamp.proto",
+	_protoDuration:  "google/protobuf/duration.proto",
+	_protoValue:     "google/protobuf/struct.proto",
+}
+
+// _protoWrappers are the wrapper types of the nullable scalar types
+var _protoWrappers = map[string]string{
+	"int32":  "google.protobuf.Int32Value",
+	"int64":  "google.protobuf.Int64Value",
+	"float":  "google.protobuf.FloatValue",
+	"double": "google.protobuf.DoubleValue",
+	"bool":   "google.protobuf.BoolValue",
+	"string": "google.protobuf.StringValue",
+	"bytes":  "google.protobuf.BytesValue",
+}
+
+// protoLock holds the numbers given to the fields of the messages and to the
+// values of the enums, so a regeneration never renumbers them
+type protoLock struct {
+	Messages map[string]*protoLockEntry `json:"messages"`
+	Enums    map[string]*protoLockEntry `json:"enums"`
+}
+
+// protoLockEntry maps field names to numbers, Reserved holds the fields that
+// were removed, whose numbers are never given to other fields
+type protoLockEntry struct {
+	Fields   map[string]int `json:"fields"`
+	Reserved map[string]int `json:"reserved,omitempty"`
+}
+
+type protoField struct {
+	Name    string
+	Type    string
+	Number  int
+	Comment string
+}
+
+// protoDefinition is a message or an enum
+type protoDefinition struct {
+	Name     string
+	Comment  string
+	Fields   []protoField
+	Reserved []protoField
+}
+
+type protoData struct {
+	Package   string
+	GoPackage string
+	Imports   []string
+	Enums     []protoDefinition
+	Messages  []protoDefinition
+}
+
+func lockEntry(entries map[string]*protoLockEntry, name string) *protoLockEntry {
+	entry, ok := entries[name]
+	if !ok {
+		entry = &protoLockEntry{
+			Fields: make(map[string]int),
+		}
+		entries[name] = entry
+	}
+
+	if entry.Fields == nil {
+		entry.Fields = make(map[string]int)
+	}
+
+	return entry
+}
+
+// number returns the locked number of a field, giving the next free number to
+// new fields. A removed field that comes back gets its number back
+func (ple *protoLockEntry) number(name string, first int) int {
+	if number, ok := ple.Fields[name]; ok {
+		return number
+	}
+
+	if number, ok := ple.Reserved[name]; ok {
+		delete(ple.Reserved, name)
+		ple.Fields[name] = number
+		return number
+	}
+
+	next := first
+	for _, numbers := range []map[string]int{ple.Fields, ple.Reserved} {
+		for _, number := range numbers {
+			next = max(next, number+1)
+		}
+	}
+
+	ple.Fields[name] = next
+
+	return next
+}
+
+// reserve moves the fields that are not in use to the reserved ones, returning
+// them sorted by number
+func (ple *protoLockEntry) reserve(inUse []string) []protoField {
+	for name, number := range ple.Fields {
+		if slices.Contains(inUse, name) {
+			continue
+		}
+
+		if ple.Reserved == nil {
+			ple.Reserved = make(map[string]int)
+		}
+
+		ple.Reserved[name] = number
+		delete(ple.Fields, name)
+	}
+
+	reserved := make([]protoField, 0, len(ple.Reserved))
+	for name, number := range ple.Reserved {
+		reserved = append(reserved, protoField{Name: name, Number: number})
+	}
+
+	slices.SortFunc(reserved, func(a, b protoField) int {
+		return a.Number - b.Number
+	})
+
+	return reserved
+}
+
+func readProtoLock(path string) (*protoLock, error) {
+	lock := &protoLock{
+		Messages: make(map[string]*protoLockEntry),
+		Enums:    make(map[string]*protoLockEntry),
+	}
+
+	content, exists, err := readExistingFile(path)
+	if err != nil || !exists {
+		return lock, err
+	}
+
+	if err := json.Unmarshal(content, lock); err != nil {
+		return nil, fmt.Errorf("failed to parse the proto lock file [%s], got error [%s]", path, err.Error())
+	}
+
+	if lock.Messages == nil {
+		lock.Messages = make(map[string]*protoLockEntry)
+	}
+
+	if lock.Enums == nil {
+		lock.Enums = make(map[string]*protoLockEntry)
+	}
+
+	return lock, nil
+}
+
+func protoEnumValueName(enum *PgEnum, value string) string {
+	return strcase.ToScreamingSnake(enum.Name + "_" + value)
+}
+
+// protoType returns the type of a column, nullable scalars are wrapped so
+// null can be told apart from the zero value
+func protoType(schema *PgSchema, column *PgColumn) string {
+	sqlDataType := strings.ToLower(column.SqlDataType)
+	if element, ok := strings.CutPrefix(sqlDataType, "_"); ok {
+		return "repeated " + protoScalarType(schema, element)
+	}
+
+	scalarType := protoScalarType(schema, sqlDataType)
+	if !column.Nullable {
+		return scalarType
+	}
+
+	if wrapper, ok := _protoWrappers[scalarType]; ok {
+		return wrapper
+	}
+
+	if schema.enum(sqlDataType) != nil {
+		return "optional " + scalarType
+	}
+
+	return scalarType
+}
+
+func protoScalarType(schema *PgSchema, sqlDataType string) string {
+	if enum := schema.enum(sqlDataType); enum != nil {
+		return strcase.ToCamel(enum.Name)
+	}
+
+	switch sqlDataType {
+	case "int2", "int4", "smallint", "integer":
+		return "int32"
+	case "int8", "bigint", "oid":
+		return "int64"
+	case "float4", "real":
+		return "float"
+	case "float8", "double precision":
+		return "double"
+	case "bool", "boolean":
+		return "bool"
+	case "bytea":
+		return "bytes"
+	case "timestamp", "timestamptz", "date":
+		return _protoTimestamp
+	case "interval":
+		return _protoDuration
+	case "json", "jsonb":
+		return _protoValue
+	default:
+		return "string"
+	}
+}
+
+// protoImports lists the files declaring the well known types used by the
+// messages
+func protoImports(messages []protoDefinition) []string {
+	var imports []string
+	for _, message := range messages {
+		for _, field := range message.Fields {
+			fieldType := strings.TrimPrefix(field.Type, "repeated ")
+			path, ok := _protoImports[fieldType]
+			if !ok && strings.HasPrefix(fieldType, "google.protobuf.") {
+				path, ok = "google/protobuf/wrappers.proto", true
+			}
+
+			if ok && !slices.Contains(imports, path) {
+				imports = append(imports, path)
+			}
+		}
+	}
+
+	slices.Sort(imports)
+
+	return imports
+}
+
+func (pcg *PgCodeGenerator) generateCodeForProto(model *PgSchema, schema ConfigSchema) error {
+	log.Printf("Generating protobuf messages for tables and views")
+
+	name := strcase.ToSnake(model.Name)
+	lockPath := filepath.Join(schema.Proto.Dest, name+".proto.lock")
+
+	lock, err := readProtoLock(lockPath)
+	if err != nil {
+		return err
+	}
+
+	data := &protoData{
+		Package:   schema.Proto.Package,
+		GoPackage: schema.Proto.GoPackage,
+	}
+
+	for i := range model.Enums {
+		enum := &model.Enums[i]
+		definition := protoDefinition{Name: strcase.ToCamel(enum.Name)}
+		entry := lockEntry(lock.Enums, definition.Name)
+
+		unspecified := protoEnumValueName(enum, "unspecified")
+		definition.Fields = append(definition.Fields, protoField{Name: unspecified})
+
+		var inUse []string
+		for _, value := range enum.Values {
+			valueName := protoEnumValueName(enum, value)
+			if valueName == unspecified {
+				return fmt.Errorf("enum [%s] has the value [%s], which is the zero value of its protobuf enum", enum.Name, value)
+			}
+
+			definition.Fields = append(definition.Fields, protoField{
+				Name:   valueName,
+				Number: entry.number(valueName, 1),
+			})
+			inUse = append(inUse, valueName)
+		}
+
+		definition.Reserved = entry.reserve(inUse)
+		data.Enums = append(data.Enums, definition)
+	}
+
+	for i := range model.Tables {
+		table := &model.Tables[i]
+		if schema.ShouldIgnore(table.Name) {
+			log.Printf("- Ignored protobuf generation for %s [%s]\n", table.Kind, table.Name)
+			continue
+		}
+
+		definition := protoDefinition{
+			Name:    table.entityName(),
+			Comment: table.Comment,
+		}
+		entry := lockEntry(lock.Messages, definition.Name)
+
+		var inUse []string
+		for j := range table.Columns {
+			column := &table.Columns[j]
+			fieldName := strcase.ToSnake(column.Name)
+
+			definition.Fields = append(definition.Fields, protoField{
+				Name:    fieldName,
+				Type:    protoType(model, column),
+				Number:  entry.number(fieldName, 1),
+				Comment: column.Comment,
+			})
+			inUse = append(inUse, fieldName)
+		}
+
+		definition.Reserved = entry.reserve(inUse)
+		data.Messages = append(data.Messages, definition)
+	}
+
+	data.Imports = protoImports(data.Messages)
+
+	code, err := executeTemplate(_protoSchemaGoTemplate, data)
+	if err != nil {
+		return fmt.Errorf("failed to generate protobuf messages for schema [%s], got error [%s]", model.Name, err.Error())
+	}
+
+	lockContent, err := json.MarshalIndent(lock, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode the proto lock file [%s], got error [%s]", lockPath, err.Error())
+	}
+
+	pcg.addFile(filepath.Join(schema.Proto.Dest, name+".proto"), []byte(code), "schema ["+model.Name+"]")
+	pcg.addFile(lockPath, append(lockContent, '\n'), "field numbers of schema ["+model.Name+"]")
+
+	return nil
+}
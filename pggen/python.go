@@ -0,0 +1,309 @@
+package pggen
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"text/template"
+
+	_ "embed"
+
+	"github.com/iancoleman/strcase"
+)
+
+var (
+	//go:embed templates/python/model.txt
+	_pythonModelTemplate string
+
+	//go:embed templates/python/enums.txt
+	_pythonEnumsTemplate string
+
+	//go:embed templates/python/init.txt
+	_pythonInitTemplate string
+
+	_pythonModelGoTemplate = mustParsePythonTemplate("model", _pythonModelTemplate)
+	_pythonEnumsGoTemplate = mustParsePythonTemplate("enums", _pythonEnumsTemplate)
+	_pythonInitGoTemplate  = mustParsePythonTemplate("init", _pythonInitTemplate)
+)
+
+const (
+	_pythonDataclass = "dataclass"
+	_pythonPydantic  = "pydantic"
+)
+
+var (
+	_pythonIdentifierRegexp    = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*$`)
+	_pythonNonIdentifierRegexp = regexp.MustCompile(`[^A-Za-z0-9]+`)
+)
+
+var _pythonKeywords = []string{
+	"False", "None", "True", "and", "as", "assert", "async", "await", "break", "class", "continue",
+	"def", "del", "elif", "else", "except", "finally", "for", "from", "global", "if", "import",
+	"in", "is", "lambda", "nonlocal", "not", "or", "pass", "raise", "return", "try", "while",
+	"with", "yield",
+}
+
+// _pythonFuncs are the helper functions of the Python templates, on top of
+// the ones of every template
+var _pythonFuncs = template.FuncMap{
+	"pyField":      pythonField,
+	"pyImports":    pythonImports,
+	"pyEnumMember": pythonEnumMember,
+	"pyString":     typeScriptString,
+	"pyDocstring":  pythonDocstring,
+}
+
+// pythonData is the data the Python templates are executed with, Pydantic
+// tells if the models are Pydantic models instead of dataclasses
+type pythonData struct {
+	Schema   *PgSchema
+	Table    *PgTable
+	Pydantic bool
+	Exports  []pythonExport
+}
+
+// pythonExport is a module re-exported by the package __init__.py
+type pythonExport struct {
+	Module string
+	Names  []string
+}
+
+func mustParsePythonTemplate(name, text string) *template.Template {
+	return template.Must(template.New(name).Funcs(_templateFuncs).Funcs(_pythonFuncs).Parse(text))
+}
+
+// pythonType returns the type psycopg reads a column as, without None
+func pythonType(schema *PgSchema, sqlDataType string) string {
+	sqlDataType = strings.ToLower(sqlDataType)
+	if element, ok := strings.CutPrefix(sqlDataType, "_"); ok {
+		return "list[" + pythonType(schema, element) + "]"
+	}
+
+	if enum := schema.enum(sqlDataType); enum != nil {
+		return strcase.ToCamel(enum.Name)
+	}
+
+	switch sqlDataType {
+	case "int2", "int4", "int8", "oid", "smallint", "integer", "bigint":
+		return "int"
+	case "float4", "float8", "real", "double precision":
+		return "float"
+	case "numeric", "decimal":
+		return "decimal.Decimal"
+	case "bool", "boolean":
+		return "bool"
+	case "uuid":
+		return "uuid.UUID"
+	case "timestamp", "timestamptz":
+		return "datetime.datetime"
+	case "date":
+		return "datetime.date"
+	case "time", "timetz":
+		return "datetime.time"
+	case "interval":
+		return "datetime.timedelta"
+	case "bytea":
+		return "bytes"
+	case "text", "varchar", "bpchar", "char", "citext", "name", "money", "inet", "cidr", "macaddr":
+		return "str"
+	default:
+		return "Any"
+	}
+}
+
+// pythonFieldName returns the attribute name of a column, which follows the
+// JSON tags of the Go entities unless it is a Python keyword
+func pythonFieldName(column *PgColumn) string {
+	name := strcase.ToSnake(column.Name)
+	if slices.Contains(_pythonKeywords, name) {
+		return name + "_"
+	}
+
+	return name
+}
+
+func pythonField(schema *PgSchema, column *PgColumn, pydantic bool) string {
+	var sb strings.Builder
+	sb.WriteString(pythonFieldName(column))
+	sb.WriteString(": ")
+	sb.WriteString(pythonType(schema, column.SqlDataType))
+
+	if column.Nullable {
+		sb.WriteString(" | None")
+	}
+
+	if name := strcase.ToSnake(column.Name); pydantic && name != pythonFieldName(column) {
+		sb.WriteString(" = Field(alias=")
+		sb.WriteString(typeScriptString(name))
+		sb.WriteString(")")
+	}
+
+	return sb.String()
+}
+
+// pythonDocstring returns the text as the docstring of a class, indented as
+// its body
+func pythonDocstring(text string) string {
+	text = strings.ReplaceAll(text, `\`, `\\`)
+	text = strings.ReplaceAll(text, `"""`, `\"\"\"`)
+	if strings.HasSuffix(text, `"`) {
+		text = strings.TrimSuffix(text, `"`) + `\"`
+	}
+
+	lines := strings.Split(text, "\n")
+	if len(lines) == 1 {
+		return `"""` + text + `"""`
+	}
+
+	return `"""` + lines[0] + "\n" + commentLines("    ", strings.Join(lines[1:], "\n")) + "\n    " + `"""`
+}
+
+// pythonEnumMember returns the member name of an enum value, prefixed when
+// it is not an identifier
+func pythonEnumMember(value string) string {
+	name := strcase.ToScreamingSnake(value)
+	if !_pythonIdentifierRegexp.MatchString(name) {
+		return "V_" + strings.Trim(_pythonNonIdentifierRegexp.ReplaceAllString(name, "_"), "_")
+	}
+
+	return name
+}
+
+// pythonImports returns the import block of a model module, standard library
+// imports first, then the third party and the package ones
+func pythonImports(schema *PgSchema, table *PgTable, pydantic bool) string {
+	var modules, enums []string
+	usesAny, usesAlias := false, false
+
+	for _, column := range table.Columns {
+		usesAlias = usesAlias || pythonFieldName(&column) != strcase.ToSnake(column.Name)
+
+		pyType := pythonType(schema, column.SqlDataType)
+		for _, module := range []string{"datetime", "decimal", "uuid"} {
+			if strings.Contains(pyType, module+".") && !slices.Contains(modules, module) {
+				modules = append(modules, module)
+			}
+		}
+
+		usesAny = usesAny || strings.Contains(pyType, "Any")
+
+		enum := schema.enum(strings.TrimPrefix(strings.ToLower(column.SqlDataType), "_"))
+		if enum != nil && !slices.Contains(enums, strcase.ToCamel(enum.Name)) {
+			enums = append(enums, strcase.ToCamel(enum.Name))
+		}
+	}
+
+	slices.Sort(modules)
+	slices.Sort(enums)
+
+	var standard []string
+	for _, module := range modules {
+		standard = append(standard, "import "+module)
+	}
+
+	if !pydantic {
+		standard = append(standard, "from dataclasses import dataclass")
+	}
+
+	if usesAny {
+		standard = append(standard, "from typing import Any")
+	}
+
+	groups := []string{strings.Join(standard, "\n")}
+	if pydantic {
+		if usesAlias {
+			groups = append(groups, "from pydantic import BaseModel, Field")
+		} else {
+			groups = append(groups, "from pydantic import BaseModel")
+		}
+	}
+
+	if len(enums) > 0 {
+		groups = append(groups, "from .enums import "+strings.Join(enums, ", "))
+	}
+
+	return strings.TrimPrefix(strings.Join(groups, "\n\n"), "\n\n")
+}
+
+func (pcg *PgCodeGenerator) addPythonFile(
+	tmpl *template.Template,
+	data *pythonData,
+	path string,
+	source string,
+) error {
+	code, err := executeTemplate(tmpl, data)
+	if err != nil {
+		return fmt.Errorf("failed to generate Python code for %s, got error [%s]", source, err.Error())
+	}
+
+	pcg.addFile(path, []byte(code), source)
+
+	return nil
+}
+
+func (pcg *PgCodeGenerator) generateCodeForPython(model *PgSchema, schema ConfigSchema) error {
+	log.Printf("Generating Python code for tables and views")
+
+	rootDirectory := schema.Python.Dest
+	data := &pythonData{
+		Schema:   model,
+		Pydantic: schema.Python.Style == _pythonPydantic,
+	}
+
+	if len(model.Enums) > 0 {
+		err := pcg.addPythonFile(
+			_pythonEnumsGoTemplate,
+			data,
+			filepath.Join(rootDirectory, "enums.py"),
+			"enums of schema ["+model.Name+"]")
+		if err != nil {
+			return err
+		}
+
+		export := pythonExport{Module: "enums"}
+		for _, enum := range model.Enums {
+			export.Names = append(export.Names, strcase.ToCamel(enum.Name))
+		}
+
+		data.Exports = append(data.Exports, export)
+	}
+
+	for i := range model.Tables {
+		table := &model.Tables[i]
+		if schema.ShouldIgnore(table.Name) {
+			log.Printf("- Ignored Python code generation for %s [%s]\n", table.Kind, table.Name)
+			continue
+		}
+
+		module := strcase.ToSnake(table.Name)
+		tableData := *data
+		tableData.Table = table
+
+		err := pcg.addPythonFile(
+			_pythonModelGoTemplate,
+			&tableData,
+			filepath.Join(rootDirectory, module+".py"),
+			table.Kind+" ["+table.Name+"]")
+		if err != nil {
+			return err
+		}
+
+		data.Exports = append(data.Exports, pythonExport{
+			Module: module,
+			Names:  []string{table.entityName()},
+		})
+	}
+
+	slices.SortFunc(data.Exports, func(a, b pythonExport) int {
+		return strings.Compare(a.Module, b.Module)
+	})
+
+	return pcg.addPythonFile(
+		_pythonInitGoTemplate,
+		data,
+		filepath.Join(rootDirectory, "__init__.py"),
+		"schema ["+model.Name+"]")
+}
@@ -0,0 +1,680 @@
+package pggen
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"go/format"
+	"go/token"
+	"log"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/iancoleman/strcase"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/stdlib"
+)
+
+const (
+	_queryOne  = "one"
+	_queryMany = "many"
+	_queryExec = "exec"
+)
+
+var _queryAnnotationRegexp = regexp.MustCompile(`^--\s*name:\s*([A-Za-z_][A-Za-z0-9_]*)\s+:([a-z]+)\s*$`)
+
+// PgQuery is an annotated query of a query file
+type PgQuery struct {
+	Name    string          `json:"name,omitempty"`
+	Command string          `json:"command,omitempty"`
+	SQL     string          `json:"sql,omitempty"`
+	File    string          `json:"file,omitempty"`
+	Line    int             `json:"line,omitempty"`
+	Params  []PgFunctionArg `json:"params,omitempty"`
+	Columns []PgColumn      `json:"columns,omitempty"`
+	Table   string          `json:"table,omitempty"`
+
+	rowEntity   string
+	goFunction  string
+	annotations map[string]string
+}
+
+func (q *PgQuery) location() string {
+	return q.File + ":" + strconv.Itoa(q.Line)
+}
+
+func (q *PgQuery) goName() string {
+	if !strIsEmpty(q.goFunction) {
+		return q.goFunction
+	}
+
+	return strcase.ToCamel(q.Name)
+}
+
+func (q *PgQuery) rowType() string {
+	if len(q.Columns) == 1 {
+		col := q.Columns[0]
+		if col.Nullable && col.GoDataType != "any" {
+			return "*" + col.GoDataType
+		}
+
+		return col.GoDataType
+	}
+
+	if q.rowEntity != "" {
+		return q.rowEntity
+	}
+
+	return q.goName() + "Row"
+}
+
+func (q *PgQuery) hasRowStruct() bool {
+	return len(q.Columns) > 1
+}
+
+// declareGoNames declares the function and the row type of the query in the
+// package
+func (q *PgQuery) declareGoNames(names *goNames) error {
+	source := "query [" + q.Name + "] at [" + q.location() + "]"
+	if err := names.declare(q.goName(), source); err != nil {
+		return err
+	}
+
+	if !q.hasRowStruct() || q.rowEntity != "" {
+		return nil
+	}
+
+	if err := names.declare(q.rowType(), "row type of "+source); err != nil {
+		return err
+	}
+
+	return declareRowFields(names.schema, q.rowType(), q.Columns)
+}
+
+func (q *PgQuery) rowTable() *PgTable {
+	return &PgTable{
+		Kind:    _table,
+		Name:    q.Name,
+		Columns: q.Columns,
+	}
+}
+
+func (q *PgQuery) goParams() string {
+	var sb strings.Builder
+
+	for _, param := range q.Params {
+		sb.WriteString(", ")
+		sb.WriteString(param.Name)
+		sb.WriteString(" ")
+		sb.WriteString(param.GoDataType)
+	}
+
+	return sb.String()
+}
+
+func (q *PgQuery) goQuery() string {
+	var sb strings.Builder
+	sb.WriteString("const query = `")
+	sb.WriteString(q.SQL)
+	sb.WriteString("`\n\nargs := []any{")
+
+	for i, param := range q.Params {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+
+		sb.WriteString(param.Name)
+	}
+
+	sb.WriteString("}")
+
+	return sb.String()
+}
+
+func (q *PgQuery) goScanFields() string {
+	if !q.hasRowStruct() {
+		return "&entity"
+	}
+
+	return q.rowTable().goSelectManyScanFields()
+}
+
+func (q *PgQuery) template() *template.Template {
+	switch q.Command {
+	case _queryOne:
+		return _functionOneGoTemplate
+	case _queryMany:
+		return _functionManyGoTemplate
+	default:
+		return _functionExecGoTemplate
+	}
+}
+
+func (q *PgQuery) values() map[string]string {
+	resultType := q.rowType()
+	zeroValue := "entity"
+	result := "entity"
+	if q.hasRowStruct() {
+		resultType = "*" + resultType
+		zeroValue = "nil"
+		result = "&entity"
+	}
+
+	return map[string]string{
+		"goFunctionName":       q.goName(),
+		"goFunctionParams":     q.goParams(),
+		"goFunctionQuery":      q.goQuery(),
+		"goFunctionRowType":    q.rowType(),
+		"goFunctionResultType": resultType,
+		"goFunctionZeroValue":  zeroValue,
+		"goFunctionResult":     result,
+		"goFunctionScanFields": q.goScanFields(),
+	}
+}
+
+// rewriteNamedParams replaces @name parameters, which are not understood by
+// Postgres, by positional ones, skipping quoted strings, identifiers and comments
+func rewriteNamedParams(sql string) (string, []string) {
+	var sb strings.Builder
+	var names []string
+
+	for i := 0; i < len(sql); i++ {
+		char := sql[i]
+
+		switch {
+		case char == '\'' || char == '"':
+			end := strings.IndexByte(sql[i+1:], char)
+			if end < 0 {
+				sb.WriteString(sql[i:])
+				return sb.String(), names
+			}
+
+			sb.WriteString(sql[i : i+end+2])
+			i += end + 1
+
+		case char == '-' && strings.HasPrefix(sql[i:], "--"):
+			end := strings.IndexByte(sql[i:], '\n')
+			if end < 0 {
+				sb.WriteString(sql[i:])
+				return sb.String(), names
+			}
+
+			sb.WriteString(sql[i : i+end])
+			i += end - 1
+
+		case char == '@' && i+1 < len(sql) && isIdentifierStart(sql[i+1]):
+			end := i + 1
+			for end < len(sql) && isIdentifierPart(sql[end]) {
+				end++
+			}
+
+			name := sql[i+1 : end]
+			position := slices.Index(names, name)
+			if position < 0 {
+				names = append(names, name)
+				position = len(names) - 1
+			}
+
+			sb.WriteString("$")
+			sb.WriteString(strconv.Itoa(position + 1))
+			i = end - 1
+
+		default:
+			sb.WriteByte(char)
+		}
+	}
+
+	return sb.String(), names
+}
+
+func isIdentifierStart(char byte) bool {
+	return char == '_' || (char >= 'a' && char <= 'z') || (char >= 'A' && char <= 'Z')
+}
+
+func isIdentifierPart(char byte) bool {
+	return isIdentifierStart(char) || (char >= '0' && char <= '9')
+}
+
+func parseQueriesFile(path string) ([]PgQuery, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file [%s]", path)
+	}
+
+	var queries []PgQuery
+	var current *PgQuery
+	var sqlBuilder strings.Builder
+
+	flush := func() error {
+		if current == nil {
+			return nil
+		}
+
+		sql := strings.TrimSpace(sqlBuilder.String())
+		sql = strings.TrimSpace(strings.TrimSuffix(sql, ";"))
+		if strIsEmpty(sql) {
+			return fmt.Errorf("query [%s] at [%s] has no SQL statement", current.Name, current.location())
+		}
+
+		if strings.Contains(sql, "`") {
+			return fmt.Errorf("query [%s] at [%s] must not contain backticks", current.Name, current.location())
+		}
+
+		current.SQL = sql
+		queries = append(queries, *current)
+		sqlBuilder.Reset()
+
+		return nil
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+
+		matches := _queryAnnotationRegexp.FindStringSubmatch(strings.TrimSpace(text))
+		if matches == nil {
+			if current == nil {
+				continue
+			}
+
+			if strings.HasPrefix(strings.TrimSpace(text), "--") {
+				_, annotations := parseAnnotations(text)
+				for _, name := range slices.Sorted(maps.Keys(annotations)) {
+					if name != _notNullAnnotation {
+						return nil, fmt.Errorf("unknown annotation [@pg_gen:%s] in query [%s] at [%s]", name, current.Name, current.location())
+					}
+
+					current.annotations[name] = annotations[name]
+				}
+			}
+
+			sqlBuilder.WriteString(text)
+			sqlBuilder.WriteString("\n")
+
+			continue
+		}
+
+		if err := flush(); err != nil {
+			return nil, err
+		}
+
+		current = &PgQuery{
+			Name:        matches[1],
+			Command:     matches[2],
+			File:        path,
+			Line:        line,
+			annotations: make(map[string]string),
+		}
+
+		if !slices.Contains([]string{_queryOne, _queryMany, _queryExec}, current.Command) {
+			return nil, fmt.Errorf("query [%s] at [%s] has invalid command [:%s], it must be one of :one, :many or :exec", current.Name, current.location(), current.Command)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read file [%s]", path)
+	}
+
+	if err := flush(); err != nil {
+		return nil, err
+	}
+
+	return queries, nil
+}
+
+func loadQueries(directory string) ([]PgQuery, error) {
+	paths, err := filepath.Glob(filepath.Join(directory, "*.sql"))
+	if err != nil {
+		return nil, err
+	}
+
+	var queries []PgQuery
+	for _, path := range paths {
+		fileQueries, err := parseQueriesFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		queries = append(queries, fileQueries...)
+	}
+
+	return queries, nil
+}
+
+func (pcg *PgCodeGenerator) describeStatement(ctx context.Context, sql string) (*pgconn.StatementDescription, error) {
+	conn, err := pcg.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	var description *pgconn.StatementDescription
+	err = conn.Raw(func(driverConn any) error {
+		stdlibConn, ok := driverConn.(*stdlib.Conn)
+		if !ok {
+			return errors.New("database driver does not support statement description")
+		}
+
+		description, err = stdlibConn.Conn().PgConn().Prepare(ctx, "", sql, nil)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return description, nil
+}
+
+func (pcg *PgCodeGenerator) getPgTypeNames(ctx context.Context, oids []uint32) (map[uint32]string, error) {
+	const query = `
+	SELECT
+		t.oid,
+		UPPER(t.typname)
+	FROM
+		pg_type t
+	WHERE
+		t.oid = ANY($1::oid[])
+	`
+
+	rows, err := pcg.db.QueryContext(ctx, query, oids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	typeNames := make(map[uint32]string, len(oids))
+	for rows.Next() {
+		var oid uint32
+		var typeName string
+		if err := rows.Scan(&oid, &typeName); err != nil {
+			return nil, err
+		}
+
+		typeNames[oid] = typeName
+	}
+
+	return typeNames, nil
+}
+
+func (pcg *PgCodeGenerator) getPgRelationColumns(ctx context.Context, oid uint32) (string, map[uint16]PgColumn, error) {
+	const query = `
+	SELECT
+		c.relname,
+		a.attnum,
+		a.attname,
+		a.attnotnull
+	FROM
+		pg_attribute a
+	INNER JOIN pg_class c ON
+		c.oid = a.attrelid
+	WHERE
+		a.attrelid = $1
+		AND a.attnum > 0
+		AND NOT a.attisdropped
+	`
+
+	rows, err := pcg.db.QueryContext(ctx, query, oid)
+	if err != nil {
+		return "", nil, err
+	}
+	defer rows.Close()
+
+	var relationName string
+	columns := make(map[uint16]PgColumn)
+	for rows.Next() {
+		var attnum uint16
+		var column PgColumn
+		var notNull bool
+		if err := rows.Scan(&relationName, &attnum, &column.Name, &notNull); err != nil {
+			return "", nil, err
+		}
+
+		column.Nullable = !notNull
+		columns[attnum] = column
+	}
+
+	return relationName, columns, nil
+}
+
+func (pcg *PgCodeGenerator) describeQuery(ctx context.Context, query *PgQuery) error {
+	sql, names := rewriteNamedParams(query.SQL)
+
+	description, err := pcg.describeStatement(ctx, sql)
+	if err != nil {
+		return fmt.Errorf("failed to prepare query [%s] at [%s], got error [%s]", query.Name, query.location(), err.Error())
+	}
+
+	oids := slices.Clone(description.ParamOIDs)
+	for _, field := range description.Fields {
+		oids = append(oids, field.DataTypeOID)
+	}
+
+	typeNames, err := pcg.getPgTypeNames(ctx, oids)
+	if err != nil {
+		return err
+	}
+
+	query.SQL = sql
+	query.Params = nil
+	usedNames := make(map[string]bool, len(description.ParamOIDs))
+	for i, oid := range description.ParamOIDs {
+		name := "arg" + strconv.Itoa(i+1)
+		if i < len(names) {
+			name = strcase.ToLowerCamel(names[i])
+		}
+
+		if token.IsKeyword(name) || isReservedFunctionIdentifier(name) || usedNames[name] {
+			name += "Arg" + strconv.Itoa(i+1)
+		}
+
+		usedNames[name] = true
+		query.Params = append(query.Params, PgFunctionArg{
+			Name:        name,
+			Mode:        _inArgMode,
+			SqlDataType: typeNames[oid],
+			GoDataType:  goDataType(typeNames[oid]),
+		})
+	}
+
+	query.Columns = nil
+	outerJoin := hasOuterJoin(sql)
+	relationNames := make(map[uint32]string)
+	relationColumns := make(map[uint32]map[uint16]PgColumn)
+	for _, field := range description.Fields {
+		column := PgColumn{
+			Name:        field.Name,
+			SqlDataType: typeNames[field.DataTypeOID],
+			GoDataType:  goDataType(typeNames[field.DataTypeOID]),
+			Nullable:    true,
+		}
+
+		if field.TableOID != 0 {
+			if _, ok := relationColumns[field.TableOID]; !ok {
+				relationName, columns, err := pcg.getPgRelationColumns(ctx, field.TableOID)
+				if err != nil {
+					return err
+				}
+
+				relationNames[field.TableOID] = relationName
+				relationColumns[field.TableOID] = columns
+			}
+
+			if tableColumn, ok := relationColumns[field.TableOID][field.TableAttributeNumber]; ok && !outerJoin {
+				column.Nullable = tableColumn.Nullable
+			}
+		}
+
+		if notNullAnnotation(query.annotations, column.Name) {
+			column.Nullable = false
+		}
+
+		query.Columns = append(query.Columns, column)
+	}
+
+	if len(query.Columns) == 0 && query.Command != _queryExec {
+		return fmt.Errorf("query [%s] at [%s] returns no columns, it must use :exec or return columns, such as with RETURNING", query.Name, query.location())
+	}
+
+	query.Table = ""
+	if len(relationNames) == 1 {
+		for oid, relationName := range relationNames {
+			if selectsAllColumns(description.Fields, query.Columns, relationColumns[oid]) {
+				query.Table = relationName
+			}
+		}
+	}
+
+	return nil
+}
+
+// selectsAllColumns reports if the fields select every column of a relation
+// in its order and with its nullability, so the entity generated for that
+// relation can be reused
+func selectsAllColumns(fields []pgconn.FieldDescription, resultColumns []PgColumn, columns map[uint16]PgColumn) bool {
+	if len(fields) != len(columns) {
+		return false
+	}
+
+	attnums := slices.Sorted(maps.Keys(columns))
+	for i, field := range fields {
+		column := columns[attnums[i]]
+		if field.TableAttributeNumber != attnums[i] || field.Name != column.Name || resultColumns[i].Nullable != column.Nullable {
+			return false
+		}
+	}
+
+	return true
+}
+
+// hasOuterJoin reports if the query has a LEFT, RIGHT or FULL join, whose
+// columns can be NULL even when the column is NOT NULL in its table
+func hasOuterJoin(sql string) bool {
+	tokens, err := tokenizeSQL(sql)
+	if err != nil {
+		return true
+	}
+
+	for i := 1; i < len(tokens); i++ {
+		if !tokens[i].isKeyword("join") {
+			continue
+		}
+
+		previous := tokens[i-1]
+		if previous.isKeyword("left") || previous.isKeyword("right") || previous.isKeyword("full") || previous.isKeyword("outer") {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (pcg *PgCodeGenerator) queriesFilepath(rootDirectory, sqlPath string) string {
+	var sb strings.Builder
+	sb.WriteString(rootDirectory)
+	sb.WriteString("/")
+	sb.WriteString(strcase.ToSnake(strings.TrimSuffix(filepath.Base(sqlPath), ".sql")))
+	sb.WriteString(".sql.go")
+
+	return filepath.Clean(sb.String())
+}
+
+func (pcg *PgCodeGenerator) generateCodeForQueries(
+	queries []PgQuery,
+	tables []PgTable,
+	schema ConfigSchema,
+	names *goNames,
+	rootDirectory string,
+	packageName string,
+	tags []ConfigTag,
+) error {
+	log.Printf("Generating code for queries")
+
+	var generatedTables []PgTable
+	for _, table := range tables {
+		if table.Kind == _table && !schema.ShouldIgnore(table.Name) {
+			generatedTables = append(generatedTables, table)
+		}
+	}
+
+	var files []string
+	queriesByFile := make(map[string][]PgQuery)
+	for _, query := range queries {
+		query.goFunction = schema.Naming.goIdentifier(query.Name)
+		query.rowEntity = ""
+		var entity *PgTable
+		for i := range generatedTables {
+			if generatedTables[i].Name == query.Table && len(query.Columns) > 1 {
+				entity = &generatedTables[i]
+				query.rowEntity = entity.entityName()
+			}
+		}
+
+		schema.Naming.nameColumns(query.Columns, entity)
+		if err := query.declareGoNames(names); err != nil {
+			return err
+		}
+
+		if _, ok := queriesByFile[query.File]; !ok {
+			files = append(files, query.File)
+		}
+
+		queriesByFile[query.File] = append(queriesByFile[query.File], query)
+	}
+
+	for _, file := range files {
+		var goTypes strings.Builder
+		var rowTypes strings.Builder
+		var code strings.Builder
+		for _, query := range queriesByFile[file] {
+			if query.hasRowStruct() && query.rowEntity == "" {
+				rowTypes.WriteString("type ")
+				rowTypes.WriteString(query.rowType())
+				rowTypes.WriteString(" struct {\n")
+				row := query.rowTable()
+				row.applyGoTags(tags)
+				rowTypes.WriteString(row.goEntityFields())
+				rowTypes.WriteString("}\n\n")
+			}
+
+			goTypes.WriteString(query.goParams())
+			goTypes.WriteString(query.rowType())
+			for _, column := range query.Columns {
+				goTypes.WriteString(column.GoDataType)
+			}
+
+			queryCode, err := executeTemplate(query.template(), query.values())
+			if err != nil {
+				return fmt.Errorf("failed to generate code for query [%s] at [%s], got error [%s]", query.Name, query.location(), err.Error())
+			}
+
+			code.WriteString(queryCode)
+		}
+
+		rawCode, err := executeTemplate(_functionsGoTemplate, map[string]string{
+			"goPackageName":      packageName,
+			"goImports":          goImports(goTypes.String(), "context", "database/sql"),
+			"goFunctionRowTypes": rowTypes.String(),
+			"goFunctions":        code.String(),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to generate code for queries file [%s], got error [%s]", file, err.Error())
+		}
+
+		formattedCode, err := format.Source([]byte(rawCode))
+		if err != nil {
+			return fmt.Errorf("failed to generate code for queries file [%s], got error [%s]", file, err.Error())
+		}
+
+		pcg.addFile(pcg.queriesFilepath(rootDirectory, file), formattedCode, "queries file ["+file+"]")
+	}
+
+	return nil
+}
@@ -0,0 +1,89 @@
+package pggen
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestHasOuterJoin(t *testing.T) {
+	tests := []struct {
+		sql      string
+		expected bool
+	}{
+		{sql: "SELECT id FROM projects", expected: false},
+		{sql: "SELECT p.id FROM projects p JOIN owners o ON o.id = p.owner_id", expected: false},
+		{sql: "SELECT p.id FROM projects p INNER JOIN owners o ON o.id = p.owner_id", expected: false},
+		{sql: "SELECT p.id FROM projects p LEFT JOIN owners o ON o.id = p.owner_id", expected: true},
+		{sql: "SELECT p.id FROM projects p left outer join owners o ON o.id = p.owner_id", expected: true},
+		{sql: "SELECT p.id FROM projects p RIGHT JOIN owners o ON o.id = p.owner_id", expected: true},
+		{sql: "SELECT p.id FROM projects p FULL JOIN owners o ON true", expected: true},
+		{sql: "SELECT 'left join' AS \"left join\" FROM projects -- left join", expected: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.sql, func(t *testing.T) {
+			if actual := hasOuterJoin(test.sql); actual != test.expected {
+				t.Errorf("expected %t, got %t", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestParseQueriesFileAnnotations(t *testing.T) {
+	tests := []struct {
+		name     string
+		sql      string
+		expected map[string]string
+		err      string
+	}{
+		{
+			name:     "without annotations",
+			sql:      "-- name: CountProjects :one\nSELECT count(*) FROM projects;",
+			expected: map[string]string{},
+		},
+		{
+			name:     "not null for every column",
+			sql:      "-- name: CountProjects :one\n-- @pg_gen:not_null\nSELECT count(*) FROM projects;",
+			expected: map[string]string{_notNullAnnotation: ""},
+		},
+		{
+			name:     "not null for the listed columns",
+			sql:      "-- name: GetProject :one\n-- Owners are optional @pg_gen:not_null=id,name\nSELECT p.id, p.name, o.name FROM projects p LEFT JOIN owners o ON true;",
+			expected: map[string]string{_notNullAnnotation: "id,name"},
+		},
+		{
+			name: "unknown annotation",
+			sql:  "-- name: CountProjects :one\n-- @pg_gen:ignore\nSELECT count(*) FROM projects;",
+			err:  "unknown annotation [@pg_gen:ignore] in query [CountProjects] at [",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "queries.sql")
+			if err := os.WriteFile(path, []byte(test.sql), 0o644); err != nil {
+				t.Fatalf("failed to write queries, got error [%s]", err.Error())
+			}
+
+			queries, err := parseQueriesFile(path)
+			if !strIsEmpty(test.err) {
+				if err == nil || !strings.HasPrefix(err.Error(), test.err) {
+					t.Fatalf("expected error [%s], got [%v]", test.err, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("expected no error, got [%s]", err.Error())
+			}
+
+			if len(queries) != 1 || !reflect.DeepEqual(queries[0].annotations, test.expected) {
+				t.Errorf("expected annotations %v, got %+v", test.expected, queries)
+			}
+		})
+	}
+}
@@ -0,0 +1,584 @@
+package pggen
+
+import (
+	_ "embed"
+	"strconv"
+	"strings"
+
+	"github.com/iancoleman/strcase"
+)
+
+var (
+	//go:embed templates/go/belongs_to.txt
+	_belongsToTemplate string
+
+	//go:embed templates/go/has_many.txt
+	_hasManyTemplate string
+
+	//go:embed templates/go/join.txt
+	_joinTemplate string
+
+	_belongsToGoTemplate = mustParseSnippet("belongs_to", _belongsToTemplate)
+	_hasManyGoTemplate   = mustParseSnippet("has_many", _hasManyTemplate)
+	_joinGoTemplate      = mustParseSnippet("join", _joinTemplate)
+)
+
+type pgRelation struct {
+	Constraint PgConstraint
+	Child      *PgTable
+	Parent     *PgTable
+}
+
+func linkRelations(tables []PgTable, schemaName string, schema ConfigSchema) {
+	tablesByName := make(map[string]*PgTable, len(tables))
+	for i := range tables {
+		tables[i].references = nil
+		tables[i].referencedBy = nil
+
+		if tables[i].Kind == _table && !schema.ShouldIgnore(tables[i].Name) {
+			tablesByName[tables[i].Name] = &tables[i]
+		}
+	}
+
+	for i := range tables {
+		child, ok := tablesByName[tables[i].Name]
+		if !ok {
+			continue
+		}
+
+		for _, constraint := range child.Constraints {
+			if constraint.Type != _foreignKeyConstraint || constraint.RefSchema != schemaName {
+				continue
+			}
+
+			parent, ok := tablesByName[constraint.RefTable]
+			if !ok || len(constraint.Columns) != len(constraint.RefColumns) {
+				continue
+			}
+
+			relation := pgRelation{
+				Constraint: constraint,
+				Child:      child,
+				Parent:     parent,
+			}
+
+			if !relation.hasColumns() {
+				continue
+			}
+
+			child.references = append(child.references, relation)
+			parent.referencedBy = append(parent.referencedBy, relation)
+		}
+	}
+}
+
+func (r *pgRelation) hasColumns() bool {
+	for i := range r.Constraint.Columns {
+		if r.Child.column(r.Constraint.Columns[i]) == nil || r.Parent.column(r.Constraint.RefColumns[i]) == nil {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (r *pgRelation) columnsSuffix() string {
+	var sb strings.Builder
+	sb.WriteString("By")
+	for _, column := range r.Constraint.Columns {
+		sb.WriteString(r.Child.column(column).goName())
+	}
+
+	return sb.String()
+}
+
+// belongsToName is the name of the many-to-one navigation, derived from the
+// foreign key column when it follows the "<name>_id" convention
+func (r *pgRelation) belongsToName() string {
+	columns := r.Constraint.Columns
+	if len(columns) == 1 && strings.HasSuffix(strings.ToLower(columns[0]), "_id") {
+		field := r.Child.column(columns[0]).goName()
+		for _, suffix := range []string{"Id", "ID"} {
+			if name, ok := strings.CutSuffix(field, suffix); ok && !strIsEmpty(name) {
+				return name
+			}
+		}
+
+		return strcase.ToCamel(columns[0][:len(columns[0])-3])
+	}
+
+	name := r.Parent.entityName()
+	for _, other := range r.Child.references {
+		if other.Constraint.Name != r.Constraint.Name && other.Parent == r.Parent {
+			return name + r.columnsSuffix()
+		}
+	}
+
+	return name
+}
+
+// hasManyName is the name of the one-to-many navigation, derived from the
+// plural name of the child table
+func (r *pgRelation) hasManyName() string {
+	name := r.Child.collectionName()
+	for _, other := range r.Parent.referencedBy {
+		if other.Constraint.Name != r.Constraint.Name && other.Child == r.Child {
+			return name + r.columnsSuffix()
+		}
+	}
+
+	return name
+}
+
+func (r *pgRelation) goValues(table *PgTable, prefix string, columns []string) string {
+	var sb strings.Builder
+
+	colSize := len(columns) - 1
+	for i, column := range columns {
+		sb.WriteString(prefix)
+		sb.WriteString(table.column(column).goName())
+
+		if i < colSize {
+			sb.WriteString(", ")
+		}
+	}
+
+	return sb.String()
+}
+
+func (r *pgRelation) sqlParentFilter() string {
+	var sb strings.Builder
+
+	colSize := len(r.Constraint.RefColumns) - 1
+	for i, column := range r.Constraint.RefColumns {
+		sb.WriteString("\"")
+		sb.WriteString(column)
+		sb.WriteString("\" = $")
+		sb.WriteString(strconv.Itoa(i + 1))
+		sb.WriteString("::")
+		sb.WriteString(r.Parent.column(column).SqlDataType)
+
+		if i < colSize {
+			sb.WriteString(" AND ")
+		}
+	}
+
+	return sb.String()
+}
+
+func (r *pgRelation) goChildRelationFilters() string {
+	var sb strings.Builder
+
+	colSize := len(r.Constraint.Columns) - 1
+	for i, column := range r.Constraint.Columns {
+		sb.WriteString("NewFilter(")
+		sb.WriteString(strconv.Quote("\"" + column + "\""))
+		sb.WriteString(", \"=\", self.")
+		sb.WriteString(r.Parent.column(r.Constraint.RefColumns[i]).goName())
+		sb.WriteString(")")
+
+		if i < colSize {
+			sb.WriteString(", ")
+		}
+	}
+
+	return sb.String()
+}
+
+// sqlChildBatchFilter matches the children of every parent in a single query,
+// composite foreign keys are matched against the unnested key arrays
+func (r *pgRelation) sqlChildBatchFilter() string {
+	columns := r.Constraint.Columns
+	if len(columns) == 1 {
+		return "\"" + columns[0] + "\" = ANY($1::" + r.Child.column(columns[0]).SqlDataType + "[])"
+	}
+
+	var sb strings.Builder
+	sb.WriteString("(")
+
+	colSize := len(columns) - 1
+	for i, column := range columns {
+		sb.WriteString("\"")
+		sb.WriteString(column)
+		sb.WriteString("\"")
+
+		if i < colSize {
+			sb.WriteString(", ")
+		}
+	}
+
+	sb.WriteString(") IN (SELECT * FROM unnest(")
+
+	for i, column := range columns {
+		sb.WriteString("$")
+		sb.WriteString(strconv.Itoa(i + 1))
+		sb.WriteString("::")
+		sb.WriteString(r.Child.column(column).SqlDataType)
+		sb.WriteString("[]")
+
+		if i < colSize {
+			sb.WriteString(", ")
+		}
+	}
+
+	sb.WriteString("))")
+
+	return sb.String()
+}
+
+func (r *pgRelation) goParentKeysDeclaration() string {
+	var sb strings.Builder
+
+	colSize := len(r.Constraint.RefColumns) - 1
+	for i, column := range r.Constraint.RefColumns {
+		col := r.Parent.column(column)
+
+		sb.WriteString("keys")
+		sb.WriteString(strconv.Itoa(i))
+		sb.WriteString(" := make([]")
+
+		if col.Nullable && col.GoDataType != "any" {
+			sb.WriteString("*")
+		}
+
+		sb.WriteString(col.GoDataType)
+		sb.WriteString(", 0, len(parents))")
+
+		if i < colSize {
+			sb.WriteString("\n")
+		}
+	}
+
+	return sb.String()
+}
+
+func (r *pgRelation) goParentKeysAppend() string {
+	var sb strings.Builder
+
+	colSize := len(r.Constraint.RefColumns) - 1
+	for i, column := range r.Constraint.RefColumns {
+		key := "keys" + strconv.Itoa(i)
+
+		sb.WriteString(key)
+		sb.WriteString(" = append(")
+		sb.WriteString(key)
+		sb.WriteString(", parent.")
+		sb.WriteString(r.Parent.column(column).goName())
+		sb.WriteString(")")
+
+		if i < colSize {
+			sb.WriteString("\n")
+		}
+	}
+
+	return sb.String()
+}
+
+func (r *pgRelation) goParentKeys() string {
+	var sb strings.Builder
+
+	colSize := len(r.Constraint.RefColumns) - 1
+	for i := range r.Constraint.RefColumns {
+		sb.WriteString("keys")
+		sb.WriteString(strconv.Itoa(i))
+
+		if i < colSize {
+			sb.WriteString(", ")
+		}
+	}
+
+	return sb.String()
+}
+
+func (r *pgRelation) belongsToValues(relationName string) map[string]string {
+	return map[string]string{
+		"goRelationName":        relationName,
+		"goChildEntityName":     r.Child.entityName(),
+		"goParentEntityName":    r.Parent.entityName(),
+		"goChildRelationValues": r.goValues(r.Child, "self.", r.Constraint.Columns),
+		"goParentScanFields":    r.Parent.goSelectManyScanFields(),
+		"sqlParentSelectFields": r.Parent.sqlSelectFields(),
+		"sqlParentTableName":    r.Parent.sqlTableName(),
+		"sqlParentFilter":       r.sqlParentFilter(),
+	}
+}
+
+func (r *pgRelation) hasManyValues() map[string]string {
+	return map[string]string{
+		"goRelationName":          r.hasManyName(),
+		"goChildEntityName":       r.Child.entityName(),
+		"goParentEntityName":      r.Parent.entityName(),
+		"goChildRelationFilters":  r.goChildRelationFilters(),
+		"goChildRelationValues":   r.goValues(r.Child, "entity.", r.Constraint.Columns),
+		"goParentRelationValues":  r.goValues(r.Parent, "parent.", r.Constraint.RefColumns),
+		"goParentKeysDeclaration": r.goParentKeysDeclaration(),
+		"goParentKeysAppend":      r.goParentKeysAppend(),
+		"goParentKeys":            r.goParentKeys(),
+		"goChildScanFields":       r.Child.goSelectManyScanFields(),
+		"sqlChildSelectFields":    r.Child.sqlSelectFields(),
+		"sqlChildTableName":       r.Child.sqlTableName(),
+		"sqlChildBatchFilter":     r.sqlChildBatchFilter(),
+	}
+}
+
+func (t *PgTable) goRelationMethods() (string, error) {
+	var sb strings.Builder
+
+	for _, relation := range t.references {
+		code, err := executeTemplate(_belongsToGoTemplate, relation.belongsToValues(relation.belongsToName()))
+		if err != nil {
+			return "", err
+		}
+
+		sb.WriteString(code)
+	}
+
+	for _, relation := range t.referencedBy {
+		code, err := executeTemplate(_hasManyGoTemplate, relation.hasManyValues())
+		if err != nil {
+			return "", err
+		}
+
+		sb.WriteString(code)
+	}
+
+	return sb.String(), nil
+}
+
+type pgJoin struct {
+	Table     *PgTable
+	Relations []pgRelation
+}
+
+func (j *pgJoin) name() string {
+	var sb strings.Builder
+
+	for i, relation := range j.Relations {
+		if i > 0 {
+			sb.WriteString("And")
+		}
+
+		sb.WriteString(relation.belongsToName())
+	}
+
+	return sb.String()
+}
+
+func (j *pgJoin) entityName() string {
+	return j.Table.entityName() + "With" + j.name()
+}
+
+func (j *pgJoin) alias(relation pgRelation) string {
+	alias := strcase.ToSnake(relation.belongsToName())
+	if alias == j.Table.Name {
+		return alias + "_ref"
+	}
+
+	return alias
+}
+
+func (j *pgJoin) goVariable(relation pgRelation) string {
+	return "joined" + relation.belongsToName()
+}
+
+func (j *pgJoin) goEntityFields() string {
+	var sb strings.Builder
+
+	sb.WriteString(j.Table.entityName())
+	sb.WriteString(" ")
+	sb.WriteString(j.Table.entityName())
+	sb.WriteString(goJoinTag(j.Table.goTags, j.Table.Name, false))
+	sb.WriteString("\n")
+
+	for _, relation := range j.Relations {
+		sb.WriteString(relation.belongsToName())
+		sb.WriteString(" *")
+		sb.WriteString(relation.Parent.entityName())
+		sb.WriteString(goJoinTag(j.Table.goTags, strcase.ToSnake(relation.belongsToName()), true))
+
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
+
+func (j *pgJoin) sqlJoins() string {
+	var sb strings.Builder
+
+	for i, relation := range j.Relations {
+		alias := j.alias(relation)
+
+		if i > 0 {
+			sb.WriteString(" ")
+		}
+
+		sb.WriteString("LEFT JOIN \"")
+		sb.WriteString(relation.Parent.sqlTableName())
+		sb.WriteString("\" AS \"")
+		sb.WriteString(alias)
+		sb.WriteString("\" ON ")
+
+		colSize := len(relation.Constraint.Columns) - 1
+		for k, column := range relation.Constraint.Columns {
+			sb.WriteString("\"")
+			sb.WriteString(alias)
+			sb.WriteString("\".\"")
+			sb.WriteString(relation.Constraint.RefColumns[k])
+			sb.WriteString("\" = \"")
+			sb.WriteString(j.Table.sqlTableName())
+			sb.WriteString("\".\"")
+			sb.WriteString(column)
+			sb.WriteString("\"")
+
+			if k < colSize {
+				sb.WriteString(" AND ")
+			}
+		}
+	}
+
+	return sb.String()
+}
+
+func (j *pgJoin) sqlSelectFields() string {
+	var sb strings.Builder
+
+	writeColumns := func(alias string, columns []PgColumn) {
+		for _, col := range columns {
+			if sb.Len() > 0 {
+				sb.WriteString(", ")
+			}
+
+			sb.WriteString("\"")
+			sb.WriteString(alias)
+			sb.WriteString("\".\"")
+			sb.WriteString(col.Name)
+			sb.WriteString("\"")
+		}
+	}
+
+	writeColumns(j.Table.sqlTableName(), j.Table.Columns)
+	for _, relation := range j.Relations {
+		writeColumns(j.alias(relation), relation.Parent.Columns)
+	}
+
+	return sb.String()
+}
+
+func (j *pgJoin) goScanDeclarations() string {
+	var sb strings.Builder
+
+	for _, relation := range j.Relations {
+		variable := j.goVariable(relation)
+
+		sb.WriteString("var ")
+		sb.WriteString(variable)
+		sb.WriteString(" ")
+		sb.WriteString(relation.Parent.entityName())
+		sb.WriteString("\nvar ")
+		sb.WriteString(variable)
+		sb.WriteString("Valid bool\n")
+	}
+
+	return sb.String()
+}
+
+func (j *pgJoin) goScanFields() string {
+	var sb strings.Builder
+
+	for _, col := range j.Table.Columns {
+		if sb.Len() > 0 {
+			sb.WriteString(", ")
+		}
+
+		sb.WriteString("&entity.")
+		sb.WriteString(j.Table.entityName())
+		sb.WriteString(".")
+		sb.WriteString(col.goName())
+	}
+
+	for _, relation := range j.Relations {
+		variable := j.goVariable(relation)
+
+		for _, col := range relation.Parent.Columns {
+			sb.WriteString(", scanNullable(&")
+			sb.WriteString(variable)
+			sb.WriteString(".")
+			sb.WriteString(col.goName())
+			sb.WriteString(", &")
+			sb.WriteString(variable)
+			sb.WriteString("Valid)")
+		}
+	}
+
+	return sb.String()
+}
+
+func (j *pgJoin) goScanAssignments() string {
+	var sb strings.Builder
+
+	for _, relation := range j.Relations {
+		variable := j.goVariable(relation)
+
+		sb.WriteString("if ")
+		sb.WriteString(variable)
+		sb.WriteString("Valid {\nentity.")
+		sb.WriteString(relation.belongsToName())
+		sb.WriteString(" = &")
+		sb.WriteString(variable)
+		sb.WriteString("\n}\n\n")
+	}
+
+	return sb.String()
+}
+
+func (j *pgJoin) values() map[string]string {
+	return map[string]string{
+		"goEntityName":           j.Table.entityName(),
+		"goJoinName":             j.name(),
+		"goJoinEntityName":       j.entityName(),
+		"goJoinEntityFields":     j.goEntityFields(),
+		"goJoinScanDeclarations": j.goScanDeclarations(),
+		"goJoinScanFields":       j.goScanFields(),
+		"goJoinScanAssignments":  j.goScanAssignments(),
+		"sqlTableName":           j.Table.sqlTableName(),
+		"sqlJoins":               j.sqlJoins(),
+		"sqlJoinSelectFields":    j.sqlSelectFields(),
+	}
+}
+
+// joins lists a two table join for every many-to-one relation of the table
+// and, when there are more than one, a join with all of the related tables
+func (t *PgTable) joins() []pgJoin {
+	var joins []pgJoin
+
+	for _, relation := range t.references {
+		joins = append(joins, pgJoin{
+			Table:     t,
+			Relations: []pgRelation{relation},
+		})
+	}
+
+	if len(t.references) > 1 {
+		joins = append(joins, pgJoin{
+			Table:     t,
+			Relations: t.references,
+		})
+	}
+
+	return joins
+}
+
+func (t *PgTable) goJoinMethods() (string, error) {
+	var sb strings.Builder
+
+	for _, join := range t.joins() {
+		code, err := executeTemplate(_joinGoTemplate, join.values())
+		if err != nil {
+			return "", err
+		}
+
+		sb.WriteString(code)
+	}
+
+	return sb.String(), nil
+}
@@ -0,0 +1,216 @@
+package pggen
+
+import "testing"
+
+// relationsModel returns the named and linked tables of a schema with the
+// foreign keys that the relation names must tell apart
+func relationsModel(t *testing.T) *PgSchema {
+	t.Helper()
+
+	fk := func(name, column, refTable string) PgConstraint {
+		return PgConstraint{
+			Name:       name,
+			Type:       _foreignKeyConstraint,
+			Columns:    []string{column},
+			RefSchema:  "public",
+			RefTable:   refTable,
+			RefColumns: []string{"id"},
+		}
+	}
+
+	columns := func(names ...string) []PgColumn {
+		result := make([]PgColumn, len(names))
+		for i, name := range names {
+			result[i] = PgColumn{Name: name}
+		}
+
+		return result
+	}
+
+	model := &PgSchema{
+		Name: "public",
+		Tables: []PgTable{
+			{Kind: _table, Name: "users", Columns: columns("id")},
+			{
+				Kind:        _table,
+				Name:        "categories",
+				Columns:     columns("id", "parent_id"),
+				Constraints: []PgConstraint{fk("categories_parent_id_fkey", "parent_id", "categories")},
+			},
+			{
+				Kind:    _table,
+				Name:    "projects",
+				Columns: columns("id", "owner_id", "created_by", "updated_by", "category"),
+				Constraints: []PgConstraint{
+					fk("projects_owner_id_fkey", "owner_id", "users"),
+					fk("projects_created_by_fkey", "created_by", "users"),
+					fk("projects_updated_by_fkey", "updated_by", "users"),
+					fk("projects_category_fkey", "category", "categories"),
+				},
+			},
+			{
+				Kind:        _table,
+				Name:        "tasks",
+				Columns:     columns("id", "assignee_id", "project_id"),
+				Constraints: []PgConstraint{
+					fk("tasks_assignee_id_fkey", "assignee_id", "users"),
+					fk("tasks_project_id_fkey", "project_id", "projects"),
+				},
+			},
+			{
+				Kind:        _table,
+				Name:        "node",
+				Columns:     columns("id", "parent"),
+				Constraints: []PgConstraint{fk("node_parent_fkey", "parent", "node")},
+			},
+			{
+				Kind:        _table,
+				Name:        "audits",
+				Columns:     columns("id", "user_id"),
+				Constraints: []PgConstraint{fk("audits_user_id_fkey", "user_id", "ignored")},
+			},
+			{Kind: _table, Name: "ignored", Columns: columns("id")},
+		},
+	}
+
+	schema := ConfigSchema{
+		Ignore: []string{"ignored"},
+		Naming: ConfigNaming{
+			Initialisms: []string{"ID"},
+			Singular:    true,
+			Columns:     map[string]map[string]string{"tasks": {"assignee_id": "Assignee"}},
+		},
+	}
+
+	if err := applyNaming(model, schema); err != nil {
+		t.Fatalf("failed to name the tables, got error [%s]", err.Error())
+	}
+
+	linkRelations(model.Tables, model.Name, schema)
+
+	return model
+}
+
+func relationsTable(model *PgSchema, name string) *PgTable {
+	for i := range model.Tables {
+		if model.Tables[i].Name == name {
+			return &model.Tables[i]
+		}
+	}
+
+	return nil
+}
+
+func TestRelationNames(t *testing.T) {
+	model := relationsModel(t)
+
+	tests := []struct {
+		table      string
+		constraint string
+		belongsTo  string
+		hasMany    string
+	}{
+		{table: "projects", constraint: "projects_owner_id_fkey", belongsTo: "Owner", hasMany: "ProjectsByOwnerID"},
+		{table: "projects", constraint: "projects_created_by_fkey", belongsTo: "UserByCreatedBy", hasMany: "ProjectsByCreatedBy"},
+		{table: "projects", constraint: "projects_updated_by_fkey", belongsTo: "UserByUpdatedBy", hasMany: "ProjectsByUpdatedBy"},
+		{table: "projects", constraint: "projects_category_fkey", belongsTo: "Category", hasMany: "Projects"},
+		{table: "categories", constraint: "categories_parent_id_fkey", belongsTo: "Parent", hasMany: "Categories"},
+		{table: "tasks", constraint: "tasks_assignee_id_fkey", belongsTo: "Assignee", hasMany: "Tasks"},
+		{table: "tasks", constraint: "tasks_project_id_fkey", belongsTo: "Project", hasMany: "Tasks"},
+		{table: "node", constraint: "node_parent_fkey", belongsTo: "Node", hasMany: "Node"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.constraint, func(t *testing.T) {
+			var relation *pgRelation
+			references := relationsTable(model, test.table).references
+			for i := range references {
+				if references[i].Constraint.Name == test.constraint {
+					relation = &references[i]
+				}
+			}
+
+			if relation == nil {
+				t.Fatalf("expected relation [%s], got none", test.constraint)
+			}
+
+			if actual := relation.belongsToName(); actual != test.belongsTo {
+				t.Errorf("expected belongs to [%s], got [%s]", test.belongsTo, actual)
+			}
+
+			if actual := relation.hasManyName(); actual != test.hasMany {
+				t.Errorf("expected has many [%s], got [%s]", test.hasMany, actual)
+			}
+		})
+	}
+}
+
+func TestLinkRelationsSkipsIgnoredTables(t *testing.T) {
+	model := relationsModel(t)
+
+	if references := relationsTable(model, "audits").references; len(references) != 0 {
+		t.Errorf("expected no relation to an ignored table, got [%d]", len(references))
+	}
+
+	if referencedBy := relationsTable(model, "users").referencedBy; len(referencedBy) != 4 {
+		t.Errorf("expected [4] relations to users, got [%d]", len(referencedBy))
+	}
+}
+
+func TestJoinNames(t *testing.T) {
+	model := relationsModel(t)
+
+	tests := []struct {
+		table    string
+		entities []string
+		aliases  [][]string
+	}{
+		{
+			table: "projects",
+			entities: []string{
+				"ProjectWithOwner",
+				"ProjectWithUserByCreatedBy",
+				"ProjectWithUserByUpdatedBy",
+				"ProjectWithCategory",
+				"ProjectWithOwnerAndUserByCreatedByAndUserByUpdatedByAndCategory",
+			},
+			aliases: [][]string{
+				{"owner"},
+				{"user_by_created_by"},
+				{"user_by_updated_by"},
+				{"category"},
+				{"owner", "user_by_created_by", "user_by_updated_by", "category"},
+			},
+		},
+		{
+			table:    "tasks",
+			entities: []string{"TaskWithAssignee", "TaskWithProject", "TaskWithAssigneeAndProject"},
+			aliases:  [][]string{{"assignee"}, {"project"}, {"assignee", "project"}},
+		},
+		{table: "categories", entities: []string{"CategoryWithParent"}, aliases: [][]string{{"parent"}}},
+		{table: "node", entities: []string{"NodeWithNode"}, aliases: [][]string{{"node_ref"}}},
+		{table: "users"},
+		{table: "audits"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.table, func(t *testing.T) {
+			joins := relationsTable(model, test.table).joins()
+			if len(joins) != len(test.entities) {
+				t.Fatalf("expected [%d] joins, got [%d]", len(test.entities), len(joins))
+			}
+
+			for i, join := range joins {
+				if actual := join.entityName(); actual != test.entities[i] {
+					t.Errorf("expected entity [%s], got [%s]", test.entities[i], actual)
+				}
+
+				for j, relation := range join.Relations {
+					if actual := join.alias(relation); actual != test.aliases[i][j] {
+						t.Errorf("expected alias [%s], got [%s]", test.aliases[i][j], actual)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package pggen
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+func WriteSchemaSnapshot(path string, schemas []PgSchema) error {
+	content, err := json.MarshalIndent(schemas, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(path, append(content, '\n'), 0666); err != nil {
+		return fmt.Errorf("failed to write schema file [%s], got error [%s]", path, err.Error())
+	}
+
+	return nil
+}
+
+// loadSchemaSnapshot reads a model written by `inspect -out` in place of the
+// database, keeping only the configured schemas and the objects they include
+func (pcg *PgCodeGenerator) loadSchemaSnapshot(path string) ([]PgSchema, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema file [%s], got error [%s]", path, err.Error())
+	}
+
+	var snapshot []PgSchema
+	if err := json.Unmarshal(content, &snapshot); err != nil {
+		return nil, fmt.Errorf("failed to decode schema file [%s] as JSON, got error [%s]", path, err.Error())
+	}
+
+	snapshotSchemas := make(map[string]PgSchema, len(snapshot))
+	for _, schema := range snapshot {
+		snapshotSchemas[schema.Name] = schema
+	}
+
+	var schemas []PgSchema
+	for _, schemaName := range pcg.schemaNames() {
+		snapshotSchema, ok := snapshotSchemas[schemaName]
+		if !ok {
+			return nil, fmt.Errorf("schema [%s] is not present in schema file [%s]", schemaName, path)
+		}
+
+		schema := pcg.cfg.Schemas[schemaName]
+
+		var tables []PgTable
+		for _, table := range snapshotSchema.Tables {
+			if table.Kind == _view && !schema.IncludeViews {
+				continue
+			}
+
+			tables = append(tables, table)
+		}
+
+		var functions []PgFunction
+		if schema.IncludeFunctions {
+			functions = snapshotSchema.Functions
+		}
+
+		var queries []PgQuery
+		if !strIsEmpty(schema.Queries) {
+			queries = snapshotSchema.Queries
+		}
+
+		schemas = append(schemas, PgSchema{
+			Name:      schemaName,
+			Tables:    tables,
+			Enums:     snapshotSchema.Enums,
+			Functions: functions,
+			Queries:   queries,
+		})
+	}
+
+	return schemas, nil
+}
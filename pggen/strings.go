@@ -0,0 +1,7 @@
+package pggen
+
+import "strings"
+
+func strIsEmpty(str string) bool {
+	return len(strings.TrimSpace(str)) == 0
+}
@@ -0,0 +1,128 @@
+package pggen
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/iancoleman/strcase"
+)
+
+// tagName returns the name in the case of the tag, snake case by default
+func (ct *ConfigTag) tagName(name string) string {
+	switch ct.Case {
+	case _tagCaseCamel:
+		return strcase.ToCamel(name)
+	case _tagCaseLowerCamel:
+		return strcase.ToLowerCamel(name)
+	case _tagCaseKebab:
+		return strcase.ToKebab(name)
+	case _tagCaseScreamingSnake:
+		return strcase.ToScreamingSnake(name)
+	case _tagCaseNone:
+		return name
+	default:
+		return strcase.ToSnake(name)
+	}
+}
+
+// value returns the tag value of a column and if the column has the tag,
+// overridden values are used as they are
+func (ct *ConfigTag) value(tableName string, column *PgColumn) (string, bool) {
+	if value, ok := ct.Columns[tableName][column.Name]; ok {
+		return value, !strIsEmpty(value)
+	}
+
+	if ct.OnlyColumns {
+		return "", false
+	}
+
+	value := ct.tagName(column.Name)
+	if ct.OmitEmpty == _omitEmptyAlways || (ct.OmitEmpty == _omitEmptyNullable && column.Nullable) {
+		value += ",omitempty"
+	}
+
+	return value, true
+}
+
+func writeStructTag(sb *strings.Builder, name, value string) {
+	if sb.Len() == 0 {
+		sb.WriteString("`")
+	} else {
+		sb.WriteString(" ")
+	}
+
+	sb.WriteString(name)
+	sb.WriteString(`:"`)
+	sb.WriteString(value)
+	sb.WriteString(`"`)
+}
+
+// structTag returns the struct tag given to the column by its table
+func (c *PgColumn) structTag() string {
+	return c.goTag
+}
+
+// jsonTag returns the json tag of the column struct tag, empty when the
+// column has no json tag
+func (c *PgColumn) jsonTag() string {
+	value, ok := reflect.StructTag(strings.Trim(c.goTag, "`")).Lookup("json")
+	if !ok {
+		return ""
+	}
+
+	var sb strings.Builder
+	writeStructTag(&sb, "json", value)
+	sb.WriteString("`")
+
+	return sb.String()
+}
+
+// goStructTag returns the struct tag of a column, empty when it has no tags
+func goStructTag(tags []ConfigTag, tableName string, column *PgColumn) string {
+	var sb strings.Builder
+	for _, tag := range tags {
+		if value, ok := tag.value(tableName, column); ok {
+			writeStructTag(&sb, tag.Name, value)
+		}
+	}
+
+	if sb.Len() > 0 {
+		sb.WriteString("`")
+	}
+
+	return sb.String()
+}
+
+// applyGoTags gives the struct tags to the columns of the table, which are
+// also used by its join structs
+func (t *PgTable) applyGoTags(tags []ConfigTag) {
+	t.goTags = tags
+	for i := range t.Columns {
+		t.Columns[i].goTag = goStructTag(tags, t.Name, &t.Columns[i])
+	}
+}
+
+// goJoinTag returns the struct tag of a field of a join struct, with
+// omitempty for the optional ones. Tags given only to some columns are not
+// used
+func goJoinTag(tags []ConfigTag, name string, optional bool) string {
+	var sb strings.Builder
+	for _, tag := range tags {
+		if tag.OnlyColumns {
+			continue
+		}
+
+		value := tag.tagName(name)
+		if optional || tag.OmitEmpty == _omitEmptyAlways {
+			value += ",omitempty"
+		}
+
+		writeStructTag(&sb, tag.Name, value)
+	}
+
+	if sb.Len() > 0 {
+		sb.WriteString("`")
+	}
+
+	return sb.String()
+}
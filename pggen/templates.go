@@ -0,0 +1,180 @@
+package pggen
+
+import (
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/iancoleman/strcase"
+)
+
+// templateData is the data model the table, view and common templates are
+// executed with. Table is nil for common templates
+type templateData struct {
+	Package        string
+	EmitJsonTags   bool
+	EmitValidation bool
+	Schema         *PgSchema
+	Table          *PgTable
+}
+
+// _templateFuncs are the helper functions available to every template
+var _templateFuncs = template.FuncMap{
+	"camel":      strcase.ToCamel,
+	"lowerCamel": strcase.ToLowerCamel,
+	"snake":      strcase.ToSnake,
+	"lower":      strings.ToLower,
+	"upper":      strings.ToUpper,
+	"join":       strings.Join,
+	"add": func(a, b int) int {
+		return a + b
+	},
+	"entityName":     (*PgTable).entityName,
+	"primaryKey":     (*PgTable).primaryKey,
+	"foreignKeys":    (*PgTable).foreignKeys,
+	"constraintName": constraintGoName,
+	"fieldName":      (*PgColumn).goName,
+	"goType":         (*PgColumn).goType,
+	"jsonTag":        (*PgColumn).jsonTag,
+	"structTag":      (*PgColumn).structTag,
+	"goFields": func(prefix string, t *PgTable) string {
+		return t.goFieldList(prefix)
+	},
+	"sqlColumns":            (*PgTable).sqlSelectFields,
+	"sqlPrimaryKeyColumn":   (*PgTable).sqlPrimaryKey,
+	"sqlInsertPlaceholders": (*PgTable).sqlInsertPlaceholders,
+	"sqlUpdatePlaceholders": (*PgTable).sqlUpdatePlaceholders,
+	"relationMethods":       (*PgTable).goRelationMethods,
+	"joinMethods":           (*PgTable).goJoinMethods,
+	"validateMethod":        (*PgTable).goValidateMethod,
+	"goImports":             goTableImports,
+	"comment":               commentLines,
+}
+
+// commentLines prefixes every line of the text, such as with "// " for Go
+// comments
+func commentLines(prefix, text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(prefix+line, " ")
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func constraintGoName(t *PgTable, c *PgConstraint) string {
+	return c.goName(t)
+}
+
+func parseTemplate(name, text string) (*template.Template, error) {
+	return template.New(name).Funcs(_templateFuncs).Option("missingkey=error").Parse(text)
+}
+
+func mustParseTemplate(name, text string) *template.Template {
+	return template.Must(parseTemplate(name, text))
+}
+
+// mustParseSnippet parses the template of a generated code snippet, which is
+// executed with the snippet values instead of the template data model
+func mustParseSnippet(name, text string) *template.Template {
+	return template.Must(template.New(name).Option("missingkey=error").Parse(text))
+}
+
+func executeTemplate(tmpl *template.Template, data any) (string, error) {
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return "", err
+	}
+
+	return sb.String(), nil
+}
+
+// renderGoTemplate executes the template and formats the result, files not
+// ending in .go are left as they are
+func renderGoTemplate(tmpl *template.Template, path string, data any) ([]byte, error) {
+	code, err := executeTemplate(tmpl, data)
+	if err != nil {
+		return nil, err
+	}
+
+	if filepath.Ext(path) != ".go" {
+		return []byte(code), nil
+	}
+
+	return format.Source([]byte(code))
+}
+
+// customTemplate is a template adding a file for every object of its kind,
+// the file path is given by executing the output template
+type customTemplate struct {
+	Kind     string
+	Template *template.Template
+	Output   *template.Template
+}
+
+type goTemplates struct {
+	Table  *template.Template
+	View   *template.Template
+	Common *template.Template
+	Custom []customTemplate
+}
+
+func loadTemplateFile(path string) (*template.Template, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template [%s], got error [%s]", path, err.Error())
+	}
+
+	tmpl, err := parseTemplate(filepath.Base(path), string(content))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template [%s], got error [%s]", path, err.Error())
+	}
+
+	return tmpl, nil
+}
+
+// loadGoTemplates returns the built in templates, replaced or followed by the
+// configured ones
+func loadGoTemplates(configTemplates []ConfigTemplate) (*goTemplates, error) {
+	templates := &goTemplates{
+		Table:  _tableGoTemplate,
+		View:   _viewGoTemplate,
+		Common: _commonGoTemplate,
+	}
+
+	for _, configTemplate := range configTemplates {
+		tmpl, err := loadTemplateFile(configTemplate.Path)
+		if err != nil {
+			return nil, err
+		}
+
+		if !strIsEmpty(configTemplate.Output) {
+			output, err := parseTemplate(configTemplate.Path+" output", configTemplate.Output)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse output of template [%s], got error [%s]", configTemplate.Path, err.Error())
+			}
+
+			templates.Custom = append(templates.Custom, customTemplate{
+				Kind:     configTemplate.Kind,
+				Template: tmpl,
+				Output:   output,
+			})
+
+			continue
+		}
+
+		switch configTemplate.Kind {
+		case _table:
+			templates.Table = tmpl
+		case _view:
+			templates.View = tmpl
+		case _common:
+			templates.Common = tmpl
+		}
+	}
+
+	return templates, nil
+}
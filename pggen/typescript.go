@@ -0,0 +1,219 @@
+package pggen
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"text/template"
+
+	_ "embed"
+
+	"github.com/iancoleman/strcase"
+)
+
+var (
+	//go:embed templates/typescript/interface.txt
+	_typeScriptInterfaceTemplate string
+
+	//go:embed templates/typescript/enums.txt
+	_typeScriptEnumsTemplate string
+
+	//go:embed templates/typescript/index.txt
+	_typeScriptIndexTemplate string
+
+	_typeScriptInterfaceGoTemplate = mustParseTypeScriptTemplate("interface", _typeScriptInterfaceTemplate)
+	_typeScriptEnumsGoTemplate     = mustParseTypeScriptTemplate("enums", _typeScriptEnumsTemplate)
+	_typeScriptIndexGoTemplate     = mustParseTypeScriptTemplate("index", _typeScriptIndexTemplate)
+)
+
+var _typeScriptIdentifierRegexp = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// _typeScriptFuncs are the helper functions of the TypeScript templates, on
+// top of the ones of every template
+var _typeScriptFuncs = template.FuncMap{
+	"tsType":        typeScriptType,
+	"tsProperty":    typeScriptProperty,
+	"tsUnion":       typeScriptUnion,
+	"tsEnumImports": typeScriptEnumImports,
+	"tsDoc":         typeScriptDoc,
+}
+
+// typeScriptData is the data the TypeScript templates are executed with,
+// Optional tells if nullable columns are optional properties instead of
+// T | null ones
+type typeScriptData struct {
+	Schema   *PgSchema
+	Table    *PgTable
+	Optional bool
+	Modules  []string
+}
+
+func mustParseTypeScriptTemplate(name, text string) *template.Template {
+	return template.Must(template.New(name).Funcs(_templateFuncs).Funcs(_typeScriptFuncs).Parse(text))
+}
+
+func (s *PgSchema) enum(sqlDataType string) *PgEnum {
+	for i := range s.Enums {
+		if strings.EqualFold(s.Enums[i].Name, sqlDataType) {
+			return &s.Enums[i]
+		}
+	}
+
+	return nil
+}
+
+func typeScriptType(schema *PgSchema, sqlDataType string) string {
+	sqlDataType = strings.ToLower(sqlDataType)
+	if element, ok := strings.CutPrefix(sqlDataType, "_"); ok {
+		elementType := typeScriptType(schema, element)
+		if strings.Contains(elementType, " ") {
+			return "(" + elementType + ")[]"
+		}
+
+		return elementType + "[]"
+	}
+
+	if enum := schema.enum(sqlDataType); enum != nil {
+		return strcase.ToCamel(enum.Name)
+	}
+
+	switch sqlDataType {
+	case "int2", "int4", "int8", "float4", "float8", "oid", "smallint", "integer", "bigint", "real", "double precision":
+		return "number"
+	case "bool", "boolean":
+		return "boolean"
+	case "uuid", "text", "varchar", "bpchar", "char", "citext", "name", "numeric", "decimal", "money", "bytea",
+		"date", "time", "timetz", "timestamp", "timestamptz", "interval", "inet", "cidr", "macaddr":
+		return "string"
+	default:
+		return "unknown"
+	}
+}
+
+// typeScriptProperty returns the property name of a column, which follows
+// the JSON tags of the Go entities
+func typeScriptProperty(column *PgColumn) string {
+	name := strcase.ToSnake(column.Name)
+	if _typeScriptIdentifierRegexp.MatchString(name) {
+		return name
+	}
+
+	return typeScriptString(name)
+}
+
+func typeScriptString(value string) string {
+	quoted, _ := json.Marshal(value)
+	return string(quoted)
+}
+
+func typeScriptUnion(values []string) string {
+	if len(values) == 0 {
+		return "never"
+	}
+
+	quoted := make([]string, len(values))
+	for i, value := range values {
+		quoted[i] = typeScriptString(value)
+	}
+
+	return strings.Join(quoted, " | ")
+}
+
+// typeScriptDoc returns the text as a JSDoc comment, indented by the indent
+func typeScriptDoc(indent, text string) string {
+	text = strings.ReplaceAll(text, "*/", "*\\/")
+	if !strings.Contains(text, "\n") {
+		return indent + "/** " + text + " */"
+	}
+
+	return indent + "/**\n" + commentLines(indent+" * ", text) + "\n" + indent + " */"
+}
+
+// typeScriptEnumImports lists the enum types used by the columns of a table
+func typeScriptEnumImports(schema *PgSchema, table *PgTable) []string {
+	var imports []string
+	for _, column := range table.Columns {
+		enum := schema.enum(strings.TrimPrefix(strings.ToLower(column.SqlDataType), "_"))
+		if enum != nil && !slices.Contains(imports, strcase.ToCamel(enum.Name)) {
+			imports = append(imports, strcase.ToCamel(enum.Name))
+		}
+	}
+
+	slices.Sort(imports)
+
+	return imports
+}
+
+func (pcg *PgCodeGenerator) addTypeScriptFile(
+	tmpl *template.Template,
+	data *typeScriptData,
+	path string,
+	source string,
+) error {
+	code, err := executeTemplate(tmpl, data)
+	if err != nil {
+		return fmt.Errorf("failed to generate TypeScript code for %s, got error [%s]", source, err.Error())
+	}
+
+	pcg.addFile(path, []byte(code), source)
+
+	return nil
+}
+
+func (pcg *PgCodeGenerator) generateCodeForTypeScript(model *PgSchema, schema ConfigSchema) error {
+	log.Printf("Generating TypeScript code for tables and views")
+
+	rootDirectory := schema.TypeScript.Dest
+	data := &typeScriptData{
+		Schema:   model,
+		Optional: schema.TypeScript.Nullable == _nullableAsOptional,
+	}
+
+	if len(model.Enums) > 0 {
+		err := pcg.addTypeScriptFile(
+			_typeScriptEnumsGoTemplate,
+			data,
+			filepath.Join(rootDirectory, "enums.ts"),
+			"enums of schema ["+model.Name+"]")
+		if err != nil {
+			return err
+		}
+
+		data.Modules = append(data.Modules, "enums")
+	}
+
+	for i := range model.Tables {
+		table := &model.Tables[i]
+		if schema.ShouldIgnore(table.Name) {
+			log.Printf("- Ignored TypeScript code generation for %s [%s]\n", table.Kind, table.Name)
+			continue
+		}
+
+		module := strcase.ToSnake(table.Name)
+		tableData := *data
+		tableData.Table = table
+
+		err := pcg.addTypeScriptFile(
+			_typeScriptInterfaceGoTemplate,
+			&tableData,
+			filepath.Join(rootDirectory, module+".ts"),
+			table.Kind+" ["+table.Name+"]")
+		if err != nil {
+			return err
+		}
+
+		data.Modules = append(data.Modules, module)
+	}
+
+	slices.Sort(data.Modules)
+
+	return pcg.addTypeScriptFile(
+		_typeScriptIndexGoTemplate,
+		data,
+		filepath.Join(rootDirectory, "index.ts"),
+		"schema ["+model.Name+"]")
+}
@@ -0,0 +1,397 @@
+package pggen
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const _checkIn = "in"
+
+// _checkOperators are the comparisons of check constraints validated in Go
+var _checkOperators = []string{">=", "<=", ">", "<", "=", "<>", "!="}
+
+// _checkMessages are the validation messages of the check operators
+var _checkMessages = map[string]string{
+	">=":     "must be greater than or equal to ",
+	"<=":     "must be less than or equal to ",
+	">":      "must be greater than ",
+	"<":      "must be less than ",
+	"=":      "must be ",
+	"<>":     "must not be ",
+	"!=":     "must not be ",
+	_checkIn: "must be one of ",
+}
+
+var _numericTypes = []string{"INT2", "INT4", "INT8", "OID", "FLOAT4", "FLOAT8", "NUMERIC"}
+
+// pgCheck is a condition of a check constraint that can be validated in Go,
+// the column compared with a value or, for the in operator, with a list of
+// values
+type pgCheck struct {
+	Column   string
+	Operator string
+	Values   []sqlToken
+}
+
+// parseCheck splits a check expression in the conditions it requires, false
+// when the expression is not only made of comparisons of columns with values
+// joined by AND
+func parseCheck(expression string) ([]pgCheck, bool) {
+	tokens, err := tokenizeSQL(expression)
+	if err != nil || len(tokens) == 0 {
+		return nil, false
+	}
+
+	return parseCheckConditions(withoutCasts(tokens))
+}
+
+// withoutCasts removes the type casts Postgres adds to the expressions, such
+// as the ::text of 'free'::text
+func withoutCasts(tokens []sqlToken) []sqlToken {
+	var result []sqlToken
+	for i := 0; i < len(tokens); i++ {
+		if !tokens[i].isSymbol("::") {
+			result = append(result, tokens[i])
+			continue
+		}
+
+		i++
+		for i+1 < len(tokens) && (tokens[i+1].isKeyword("varying") || tokens[i+1].isKeyword("precision")) {
+			i++
+		}
+
+		for i+1 < len(tokens) && (tokens[i+1].isSymbol("(") || tokens[i+1].isSymbol("[")) {
+			closing := matchingBracket(tokens, i+1)
+			if closing < 0 {
+				return tokens
+			}
+
+			i = closing
+		}
+	}
+
+	return result
+}
+
+// matchingBracket returns the position of the bracket closing the one at
+// start, -1 when it is not closed
+func matchingBracket(tokens []sqlToken, start int) int {
+	opening := tokens[start].Text
+	closing := map[string]string{"(": ")", "[": "]"}[opening]
+
+	for i, depth := start, 0; i < len(tokens); i++ {
+		switch {
+		case tokens[i].isSymbol(opening):
+			depth++
+		case tokens[i].isSymbol(closing):
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+
+	return -1
+}
+
+func withoutParens(tokens []sqlToken) []sqlToken {
+	for len(tokens) > 1 && tokens[0].isSymbol("(") && matchingBracket(tokens, 0) == len(tokens)-1 {
+		tokens = tokens[1 : len(tokens)-1]
+	}
+
+	return tokens
+}
+
+// splitTopLevel splits the tokens by the keyword outside of brackets, false
+// when one of the stop keywords is found outside of brackets
+func splitTopLevel(tokens []sqlToken, keyword string, stop ...string) ([][]sqlToken, bool) {
+	var parts [][]sqlToken
+	start, depth := 0, 0
+	for i, token := range tokens {
+		switch {
+		case token.isSymbol("("), token.isSymbol("["):
+			depth++
+		case token.isSymbol(")"), token.isSymbol("]"):
+			depth--
+		case depth == 0 && token.isKeyword(keyword):
+			parts = append(parts, tokens[start:i])
+			start = i + 1
+		case depth == 0 && slices.ContainsFunc(stop, token.isKeyword):
+			return nil, false
+		}
+	}
+
+	return append(parts, tokens[start:]), true
+}
+
+func parseCheckConditions(tokens []sqlToken) ([]pgCheck, bool) {
+	parts, ok := splitTopLevel(withoutParens(tokens), "and", "or", "not", "between", "is")
+	if !ok {
+		return nil, false
+	}
+
+	var checks []pgCheck
+	for _, part := range parts {
+		part = withoutParens(part)
+		if len(part) == 0 {
+			return nil, false
+		}
+
+		if nested, ok := splitTopLevel(part, "and"); ok && len(nested) > 1 {
+			nestedChecks, ok := parseCheckConditions(part)
+			if !ok {
+				return nil, false
+			}
+
+			checks = append(checks, nestedChecks...)
+			continue
+		}
+
+		check, ok := parseCheckCondition(part)
+		if !ok {
+			return nil, false
+		}
+
+		checks = append(checks, check)
+	}
+
+	return checks, true
+}
+
+// parseCheckCondition parses a comparison, such as price >= 0, tier IN
+// ('free', 'premium') or tier = ANY (ARRAY['free', 'premium'])
+func parseCheckCondition(tokens []sqlToken) (pgCheck, bool) {
+	for i, depth := 0, 0; i < len(tokens); i++ {
+		token := tokens[i]
+		switch {
+		case token.isSymbol("("), token.isSymbol("["):
+			depth++
+			continue
+		case token.isSymbol(")"), token.isSymbol("]"):
+			depth--
+			continue
+		case depth > 0:
+			continue
+		}
+
+		left, right := withoutParens(tokens[:i]), withoutParens(tokens[i+1:])
+		if len(left) != 1 && len(right) != 1 {
+			continue
+		}
+
+		if token.isKeyword(_checkIn) {
+			if !checkColumn(left) {
+				return pgCheck{}, false
+			}
+
+			values, ok := checkValueList(right)
+			return pgCheck{Column: left[0].Text, Operator: _checkIn, Values: values}, ok
+		}
+
+		if token.Kind != _symbolToken || !slices.Contains(_checkOperators, token.Text) {
+			continue
+		}
+
+		if token.Text == "=" && len(right) > 0 && right[0].isKeyword("any") {
+			array := withoutParens(right[1:])
+			if !checkColumn(left) || len(array) < 3 || !array[0].isKeyword("array") ||
+				!array[1].isSymbol("[") || matchingBracket(array, 1) != len(array)-1 {
+				return pgCheck{}, false
+			}
+
+			values, ok := checkValueList(array[2 : len(array)-1])
+			return pgCheck{Column: left[0].Text, Operator: _checkIn, Values: values}, ok
+		}
+
+		operator := token.Text
+		if checkColumn(right) {
+			left, right = right, left
+			if flipped, ok := map[string]string{">=": "<=", "<=": ">=", ">": "<", "<": ">"}[operator]; ok {
+				operator = flipped
+			}
+		}
+
+		value, ok := checkValue(right)
+		if !ok || !checkColumn(left) {
+			return pgCheck{}, false
+		}
+
+		return pgCheck{Column: left[0].Text, Operator: operator, Values: []sqlToken{value}}, true
+	}
+
+	return pgCheck{}, false
+}
+
+func checkColumn(tokens []sqlToken) bool {
+	return len(tokens) == 1 && tokens[0].Kind == _identifierToken
+}
+
+// checkValue returns the literal of the tokens, a string or a number
+func checkValue(tokens []sqlToken) (sqlToken, bool) {
+	tokens = withoutParens(tokens)
+	switch {
+	case len(tokens) == 1 && (tokens[0].Kind == _stringToken || tokens[0].Kind == _numberToken):
+		return tokens[0], true
+	case len(tokens) == 2 && tokens[0].isSymbol("-") && tokens[1].Kind == _numberToken:
+		return sqlToken{Kind: _numberToken, Text: "-" + tokens[1].Text}, true
+	default:
+		return sqlToken{}, false
+	}
+}
+
+func checkValueList(tokens []sqlToken) ([]sqlToken, bool) {
+	var values []sqlToken
+	for _, item := range splitList(tokens) {
+		value, ok := checkValue(item)
+		if !ok {
+			return nil, false
+		}
+
+		values = append(values, value)
+	}
+
+	return values, len(values) > 0
+}
+
+// goValidation returns the Go condition, such as value >= 0, and the text
+// of the values of the check for the column, false when the values do not
+// match the type of the column
+func (c *pgCheck) goValidation(numeric bool) (string, string, bool) {
+	literals := make([]string, len(c.Values))
+	texts := make([]string, len(c.Values))
+	for i, value := range c.Values {
+		texts[i] = value.Text
+		switch {
+		case numeric:
+			number, err := strconv.ParseFloat(value.Text, 64)
+			if err != nil {
+				return "", "", false
+			}
+
+			literals[i] = strconv.FormatFloat(number, 'g', -1, 64)
+		case value.Kind == _stringToken && slices.Contains([]string{"=", "<>", "!=", _checkIn}, c.Operator):
+			literals[i] = strconv.Quote(value.Text)
+		default:
+			return "", "", false
+		}
+	}
+
+	switch c.Operator {
+	case _checkIn:
+		return "!validationOneOf(value, " + strings.Join(literals, ", ") + ")", "[" + strings.Join(texts, ", ") + "]", true
+	case "<>", "!=":
+		return "value == " + literals[0], texts[0], true
+	case "=":
+		return "value != " + literals[0], texts[0], true
+	default:
+		return "!(value " + c.Operator + " " + literals[0] + ")", texts[0], true
+	}
+}
+
+func writeValidation(sb *strings.Builder, column *PgColumn, getter string, condition string, message string) {
+	sb.WriteString("\tif ")
+	if !strIsEmpty(getter) {
+		sb.WriteString(getter)
+		sb.WriteString("(self.")
+		sb.WriteString(column.goName())
+		sb.WriteString("); ok && ")
+	}
+
+	sb.WriteString(condition)
+	sb.WriteString(" {\n\t\terrs.add(")
+	sb.WriteString(strconv.Quote(column.goName()))
+	sb.WriteString(", ")
+	sb.WriteString(strconv.Quote(column.Name))
+	sb.WriteString(", ")
+	sb.WriteString(strconv.Quote(message))
+	sb.WriteString(")\n\t}\n\n")
+}
+
+// goValidateMethod returns the Validate method of the table entity, which
+// rejects the values the database would reject for its columns and simple
+// check constraints
+func (t *PgTable) goValidateMethod(schema *PgSchema) string {
+	var sb strings.Builder
+	sb.WriteString("// Validate checks the fields against the constraints of the table, returning\n")
+	sb.WriteString("// a *ValidationError with every rejected field\n")
+	sb.WriteString("func (self *")
+	sb.WriteString(t.entityName())
+	sb.WriteString(") Validate() error {\n\terrs := &ValidationError{}\n\n")
+
+	for i := range t.Columns {
+		column := &t.Columns[i]
+		if !column.Nullable && column.GoDataType == "any" {
+			writeValidation(&sb, column, "", "self."+column.goName()+" == nil", "must not be null")
+		}
+
+		if column.MaxLength > 0 {
+			writeValidation(&sb, column, "value, ok := validationLength", "value > "+strconv.Itoa(column.MaxLength),
+				"must have at most "+strconv.Itoa(column.MaxLength)+" characters")
+		}
+
+		if digits := column.Precision - column.Scale; column.Precision > 0 {
+			limit := "1e" + strconv.Itoa(digits)
+			writeValidation(&sb, column, "value, ok := validationNumber", "(value <= -"+limit+" || value >= "+limit+")",
+				"must have at most "+strconv.Itoa(digits)+" digits before the decimal point")
+		}
+
+		if enum := schema.enum(column.SqlDataType); enum != nil {
+			values := make([]string, len(enum.Values))
+			for j, value := range enum.Values {
+				values[j] = strconv.Quote(value)
+			}
+
+			writeValidation(&sb, column, "value, ok := validationString", "!validationOneOf(value, "+strings.Join(values, ", ")+")",
+				"must be one of ["+strings.Join(enum.Values, ", ")+"]")
+		}
+	}
+
+	for _, constraint := range t.Constraints {
+		if constraint.Type != _checkConstraint {
+			continue
+		}
+
+		if !t.writeCheckValidations(&sb, &constraint) {
+			sb.WriteString("\t// Not validated, check constraint [")
+			sb.WriteString(constraint.Name)
+			sb.WriteString("] could not be translated to Go\n\n")
+		}
+	}
+
+	sb.WriteString("\treturn errs.errOrNil()\n}")
+
+	return sb.String()
+}
+
+func (t *PgTable) writeCheckValidations(sb *strings.Builder, constraint *PgConstraint) bool {
+	checks, ok := parseCheck(constraint.Check)
+	if !ok {
+		return false
+	}
+
+	var validations strings.Builder
+	for _, check := range checks {
+		column := t.column(check.Column)
+		if column == nil {
+			return false
+		}
+
+		numeric := slices.Contains(_numericTypes, strings.ToUpper(column.SqlDataType))
+		condition, values, ok := check.goValidation(numeric)
+		if !ok {
+			return false
+		}
+
+		getter := "value, ok := validationString"
+		if numeric {
+			getter = "value, ok := validationNumber"
+		}
+
+		writeValidation(&validations, column, getter, condition, _checkMessages[check.Operator]+values)
+	}
+
+	sb.WriteString(validations.String())
+
+	return true
+}
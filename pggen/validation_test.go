@@ -0,0 +1,137 @@
+package pggen
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// checkText returns the check as text, such as tier in 'free', 'premium',
+// with the string values quoted to tell them apart from the numbers
+func checkText(check pgCheck) string {
+	values := make([]string, len(check.Values))
+	for i, value := range check.Values {
+		values[i] = value.Text
+		if value.Kind == _stringToken {
+			values[i] = "'" + value.Text + "'"
+		}
+	}
+
+	return check.Column + " " + check.Operator + " " + strings.Join(values, ", ")
+}
+
+func TestParseCheck(t *testing.T) {
+	tests := []struct {
+		expression string
+		expected   []string
+		ok         bool
+	}{
+		{expression: "(price >= (0)::numeric)", expected: []string{"price >= 0"}, ok: true},
+		{expression: "(price > 0)", expected: []string{"price > 0"}, ok: true},
+		{expression: "(0 < price)", expected: []string{"price > 0"}, ok: true},
+		{expression: "(10 >= stock)", expected: []string{"stock <= 10"}, ok: true},
+		{expression: "(balance >= '-100'::integer)", expected: []string{"balance >= '-100'"}, ok: true},
+		{expression: "(balance >= -100)", expected: []string{"balance >= -100"}, ok: true},
+		{expression: "((tier)::text = 'free'::text)", expected: []string{"tier = 'free'"}, ok: true},
+		{expression: "(tier <> 'banned'::character varying)", expected: []string{"tier <> 'banned'"}, ok: true},
+		{
+			expression: "((tier)::text = ANY ((ARRAY['free'::character varying, 'premium'::character varying])::text[]))",
+			expected:   []string{"tier in 'free', 'premium'"},
+			ok:         true,
+		},
+		{
+			expression: "(status = ANY (ARRAY[1, 2, 3]))",
+			expected:   []string{"status in 1, 2, 3"},
+			ok:         true,
+		},
+		{expression: "tier IN ('free', 'premium')", expected: []string{"tier in 'free', 'premium'"}, ok: true},
+		{
+			expression: "((price >= (0)::double precision) AND (price <= (1000)::double precision))",
+			expected:   []string{"price >= 0", "price <= 1000"},
+			ok:         true,
+		},
+		{
+			expression: "((a > 0) AND ((b > 0) AND (c < 10)))",
+			expected:   []string{"a > 0", "b > 0", "c < 10"},
+			ok:         true,
+		},
+		{expression: `("Price" >= 0)`, expected: []string{"Price >= 0"}, ok: true},
+		{expression: "((price > 0) OR (price IS NULL))"},
+		{expression: "((a > 0) AND ((b > 0) OR (c < 10)))"},
+		{expression: "(NOT (price > 0))"},
+		{expression: "(price BETWEEN 0 AND 10)"},
+		{expression: "(length(name) > 0)"},
+		{expression: "(price > discount)"},
+		{expression: "(price > (discount + 1))"},
+		{expression: "(tier = ANY (allowed_tiers))"},
+		{expression: "(tier = ANY (ARRAY['free', lower(name)]))"},
+		{expression: "(name ~~ 'a%'::text)"},
+		{expression: ""},
+		{expression: "(price > 'unterminated)"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.expression, func(t *testing.T) {
+			checks, ok := parseCheck(test.expression)
+			if ok != test.ok {
+				t.Fatalf("expected ok [%t], got [%t]", test.ok, ok)
+			}
+
+			var actual []string
+			for _, check := range checks {
+				actual = append(actual, checkText(check))
+			}
+
+			if !reflect.DeepEqual(actual, test.expected) {
+				t.Errorf("expected %q, got %q", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestCheckGoValidation(t *testing.T) {
+	tests := []struct {
+		expression string
+		numeric    bool
+		condition  string
+		text       string
+		ok         bool
+	}{
+		{expression: "(price >= 0.50)", numeric: true, condition: "!(value >= 0.5)", text: "0.50", ok: true},
+		{expression: "(balance >= '-100'::integer)", numeric: true, condition: "!(value >= -100)", text: "-100", ok: true},
+		{expression: "(status = 1)", numeric: true, condition: "value != 1", text: "1", ok: true},
+		{expression: "(tier <> 'banned')", condition: `value == "banned"`, text: "banned", ok: true},
+		{
+			expression: "(tier IN ('free', 'premium'))",
+			condition:  `!validationOneOf(value, "free", "premium")`,
+			text:       "[free, premium]",
+			ok:         true,
+		},
+		{expression: "(status IN (1, 2))", numeric: true, condition: "!validationOneOf(value, 1, 2)", text: "[1, 2]", ok: true},
+		{expression: "(tier >= 'b')"},
+		{expression: "(tier = 1)"},
+		{expression: "(price >= 'free')", numeric: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.expression, func(t *testing.T) {
+			checks, ok := parseCheck(test.expression)
+			if !ok || len(checks) != 1 {
+				t.Fatalf("expected one check, got %v", checks)
+			}
+
+			condition, text, ok := checks[0].goValidation(test.numeric)
+			if ok != test.ok {
+				t.Fatalf("expected ok [%t], got [%t]", test.ok, ok)
+			}
+
+			if condition != test.condition {
+				t.Errorf("expected condition [%s], got [%s]", test.condition, condition)
+			}
+
+			if text != test.text {
+				t.Errorf("expected text [%s], got [%s]", test.text, text)
+			}
+		})
+	}
+}